@@ -0,0 +1,243 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charlesaraya/gator/internal/config"
+	"github.com/charlesaraya/gator/internal/database"
+	"github.com/charlesaraya/gator/internal/migrate"
+	"github.com/charlesaraya/gator/internal/rss"
+	sqliteschema "github.com/charlesaraya/gator/internal/sql/sqlite/schema"
+	"github.com/charlesaraya/gator/internal/storage"
+	"github.com/google/uuid"
+	"github.com/pressly/goose/v3"
+)
+
+const upgradeFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<title>Upgrade</title>
+<link>https://example.com/</link>
+<item><title>First</title><link>https://example.com/first</link><guid isPermaLink="false">tag:example.com,2002:1</guid><pubDate>Sat, 07 Sep 2002 00:00:01 GMT</pubDate></item>
+<item><title>Second</title><link>https://example.com/second</link><guid isPermaLink="false">tag:example.com,2002:2</guid><pubDate>Sun, 08 Sep 2002 00:00:01 GMT</pubDate></item>
+</channel></rss>`
+
+// TestScrapeAfterGuidMigration scrapes a feed whose posts were stored before posts had guids,
+// which migration 007 keys by url, and makes sure they're matched to their items rather than duplicated.
+func TestScrapeAfterGuidMigration(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, upgradeFeed)
+	}))
+	defer server.Close()
+
+	db, _ := openTestDB(t, "sqlite://"+filepath.Join(t.TempDir(), "gator.db"))
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, sqliteschema.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.UpTo(ctx, 6); err != nil {
+		t.Fatalf("failed to migrate to version 6: %v", err)
+	}
+	userID, feedID := uuid.New(), uuid.New()
+	createdAt := time.Now().UTC().Add(-time.Hour)
+	if _, err := db.ExecContext(ctx, `INSERT INTO users (id, created_at, updated_at, name) VALUES (?, ?, ?, 'kahya')`,
+		userID, createdAt, createdAt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO feeds (id, user_id, created_at, updated_at, name, url) VALUES (?, ?, ?, ?, 'upgrade', ?)`,
+		feedID, userID, createdAt, createdAt, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range []string{"https://example.com/first", "https://example.com/second"} {
+		if _, err := db.ExecContext(ctx, `INSERT INTO posts (id, feed_id, created_at, updated_at, title, url, description, published_at) VALUES (?, ?, ?, ?, ?, ?, '', ?)`,
+			uuid.New(), feedID, createdAt, createdAt, link, link, createdAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := migrate.Up(ctx, db, config.SQLITE_DRIVER); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &State{Db: storage.NewSQLite(db), Conn: db, Out: io.Discard}
+	if err := scrapeFeeds(ctx, s, newHostLimiter(1), &aggStats{}); err != nil {
+		t.Fatalf("scrapeFeeds returned error: %v", err)
+	}
+	rows, err := db.QueryContext(ctx, `SELECT url, guid FROM posts ORDER BY url`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	want := [][2]string{
+		{"https://example.com/first", "tag:example.com,2002:1"},
+		{"https://example.com/second", "tag:example.com,2002:2"},
+	}
+	var got [][2]string
+	for rows.Next() {
+		var post [2]string
+		if err := rows.Scan(&post[0], &post[1]); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, post)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("posts after scraping = %v, want %v", got, want)
+	}
+}
+
+// TestScrapeFeedsDrainsDueFeeds makes sure one call scrapes every due feed, rather than one feed per tick.
+func TestScrapeFeedsDrainsDueFeeds(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, upgradeFeed)
+	}))
+	defer server.Close()
+
+	s := &State{Db: storage.NewMemory(), Out: io.Discard}
+	user, err := s.Db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: time.Now(), UpdatedAt: time.Now(), Name: "kahya"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		_, err := s.Db.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      fmt.Sprintf("feed %d", i),
+			Url:       fmt.Sprintf("%s/feed%d.xml", server.URL, i),
+			UserID:    user.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := &aggStats{}
+	if err := scrapeFeeds(ctx, s, newHostLimiter(2), stats); err != nil {
+		t.Fatalf("scrapeFeeds returned error: %v", err)
+	}
+	if got := stats.fetched.Load(); got != 3 {
+		t.Errorf("scrapeFeeds fetched %d feeds, want 3", got)
+	}
+}
+
+// TestScheduleOutsideUTC runs the aggregator in time zones ahead of and behind UTC, and makes sure the feeds
+// it fetched, or failed to, aren't due again before their next fetch. Set GATOR_TEST_POSTGRES_URL to a
+// scratch database to run it against PostgreSQL as well; the test deletes every user in it.
+func TestScheduleOutsideUTC(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken.xml" {
+			http.Error(w, "broken", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, upgradeFeed)
+	}))
+	defer server.Close()
+
+	stores := map[string]func(t *testing.T) storage.Store{
+		"memory": func(t *testing.T) storage.Store { return storage.NewMemory() },
+		"sqlite": func(t *testing.T) storage.Store {
+			return openTestStore(t, "sqlite://"+filepath.Join(t.TempDir(), "gator.db"))
+		},
+		"postgres": func(t *testing.T) storage.Store {
+			dbUrl := os.Getenv("GATOR_TEST_POSTGRES_URL")
+			if dbUrl == "" {
+				t.Skip("GATOR_TEST_POSTGRES_URL is not set")
+			}
+			store := openTestStore(t, dbUrl)
+			if err := store.DeleteUsers(ctx); err != nil {
+				t.Fatal(err)
+			}
+			return store
+		},
+	}
+	local := time.Local
+	t.Cleanup(func() { time.Local = local })
+	for _, zone := range []*time.Location{time.FixedZone("UTC+10", 10*60*60), time.FixedZone("UTC-10", -10*60*60)} {
+		for name, open := range stores {
+			t.Run(zone.String()+"/"+name, func(t *testing.T) {
+				time.Local = zone
+				s := &State{Db: open(t), Out: io.Discard}
+				user, err := s.Db.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: time.Now(), UpdatedAt: time.Now(), Name: "kahya"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, path := range []string{"/feed.xml", "/broken.xml"} {
+					_, err := s.Db.CreateFeed(ctx, database.CreateFeedParams{
+						ID:        uuid.New(),
+						CreatedAt: time.Now(),
+						UpdatedAt: time.Now(),
+						Name:      path,
+						Url:       server.URL + path,
+						UserID:    user.ID,
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+				stats := &aggStats{}
+				if err := scrapeFeeds(ctx, s, newHostLimiter(2), stats); err != nil {
+					t.Fatalf("scrapeFeeds returned error: %v", err)
+				}
+				if stats.fetched.Load() != 1 || stats.failed.Load() != 1 {
+					t.Fatalf("scrapeFeeds: %s, want 1 feed fetched and 1 failed", stats)
+				}
+				if feed, err := s.Db.ClaimNextFeedToFetch(ctx); !errors.Is(err, sql.ErrNoRows) {
+					t.Errorf("ClaimNextFeedToFetch = %s, %v, want no feed due", feed.Url, err)
+				}
+				for path, wantAfter := range map[string]time.Duration{"/feed.xml": rss.MinRefreshInterval, "/broken.xml": rss.Backoff(1, rss.FetchInfo{})} {
+					feed, err := s.Db.GetFeed(ctx, server.URL+path)
+					if err != nil {
+						t.Fatal(err)
+					}
+					next := time.Until(feed.NextFetchAt.Time)
+					if next < wantAfter-time.Minute || next > rss.MaxRefreshInterval+time.Minute {
+						t.Errorf("%s is next fetched in %v, want between %v and %v", path, next.Round(time.Minute), wantAfter, rss.MaxRefreshInterval)
+					}
+				}
+			})
+		}
+	}
+}
+
+// openTestDB opens the database at dbUrl the way gator does, without migrating it.
+func openTestDB(t *testing.T, dbUrl string) (*sql.DB, string) {
+	cfg := &config.Config{DBUrl: dbUrl}
+	driver, _, err := cfg.Driver()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := config.LoadDB(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, driver
+}
+
+func openTestStore(t *testing.T, dbUrl string) storage.Store {
+	db, driver := openTestDB(t, dbUrl)
+	if _, err := migrate.Up(context.Background(), db, driver); err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.New(db, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
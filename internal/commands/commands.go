@@ -1,64 +1,52 @@
 package commands
 
 import (
+	"bufio"
+	"cmp"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/charlesaraya/gator/internal/config"
 	"github.com/charlesaraya/gator/internal/database"
+	"github.com/charlesaraya/gator/internal/migrate"
 	"github.com/charlesaraya/gator/internal/rss"
+	"github.com/charlesaraya/gator/internal/storage"
 	"github.com/google/uuid"
+	"github.com/pressly/goose/v3"
 )
 
-const defaultBrowseLimit int32 = 2
+const (
+	defaultBrowseLimit     int32 = 2
+	defaultSearchLimit     int32 = 10
+	defaultAggWorkers            = 4
+	defaultAggMaxPerHost         = 2
+	maxFeedFailures              = 10
+	aggShutdownGracePeriod       = 30 * time.Second
+)
 
 type State struct {
 	Config *config.Config
-	Db     *database.Queries
-}
-
-type Command struct {
-	Name      string
-	Arguments []string
+	Db     storage.Store
+	Conn   *sql.DB
+	// In and Out are where handlers read input from and write output to, usually os.Stdin and os.Stdout.
+	In  io.Reader
+	Out io.Writer
 }
 
-type Commands struct {
-	CommandRegistry map[string]func(*State, Command) error
-}
-
-func (c *Commands) Run(s *State, cmd Command) error {
-	cmdHandler, ok := c.CommandRegistry[cmd.Name]
-	if !ok {
-		return fmt.Errorf("command '%s' not registered", cmd.Name)
-	}
-	if err := cmdHandler(s, cmd); err != nil {
-		return fmt.Errorf("failed to run command '%s': %w", cmd.Name, err)
-	}
-	return nil
-}
-
-func (c *Commands) Register(name string, f func(*State, Command) error) error {
-	if _, ok := c.CommandRegistry[name]; ok {
-		return fmt.Errorf("command '%s' already registered", name)
-	}
-	c.CommandRegistry[name] = f
-	return nil
-}
-
-func GetCommands() Commands {
-	return Commands{
-		CommandRegistry: make(map[string]func(*State, Command) error),
-	}
-}
-
-func LoginHandler(s *State, cmd Command) error {
-	if len(cmd.Arguments) != 1 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s <userName>", cmd.Name)
-	}
+func LoginHandler(ctx context.Context, s *State, cmd Command) error {
 	userName := cmd.Arguments[0]
-	if _, err := s.Db.GetUser(context.Background(), userName); err != nil {
+	if _, err := s.Db.GetUser(ctx, userName); err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 	if err := s.Config.SetUser(userName); err != nil {
@@ -68,10 +56,7 @@ func LoginHandler(s *State, cmd Command) error {
 	return nil
 }
 
-func RegisterHandler(s *State, cmd Command) error {
-	if len(cmd.Arguments) != 1 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s <userName>", cmd.Name)
-	}
+func RegisterHandler(ctx context.Context, s *State, cmd Command) error {
 	userName := cmd.Arguments[0]
 	userParams := database.CreateUserParams{
 		ID:        uuid.New(),
@@ -79,7 +64,7 @@ func RegisterHandler(s *State, cmd Command) error {
 		UpdatedAt: time.Now(),
 		Name:      userName,
 	}
-	user, err := s.Db.CreateUser(context.Background(), userParams)
+	user, err := s.Db.CreateUser(ctx, userParams)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -90,30 +75,24 @@ func RegisterHandler(s *State, cmd Command) error {
 	return nil
 }
 
-func UsersHandler(s *State, cmd Command) error {
-	if len(cmd.Arguments) != 0 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s", cmd.Name)
-	}
-	users, err := s.Db.GetUsers(context.Background())
+func UsersHandler(ctx context.Context, s *State, cmd Command) error {
+	users, err := s.Db.GetUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get users: %w", err)
 	}
 	log.Printf("Users: %v users", len(users))
 	for _, user := range users {
 		if user.Name == s.Config.UserName {
-			fmt.Printf("* %s (current)\n", user.Name)
+			fmt.Fprintf(s.Out, "* %s (current)\n", user.Name)
 		} else {
-			fmt.Printf("* %s\n", user.Name)
+			fmt.Fprintf(s.Out, "* %s\n", user.Name)
 		}
 	}
 	return nil
 }
 
-func ResetHandler(s *State, cmd Command) error {
-	if len(cmd.Arguments) != 0 {
-		return fmt.Errorf("incorrect command usage. use: %s", cmd.Name)
-	}
-	err := s.Db.DeleteUsers(context.Background())
+func ResetHandler(ctx context.Context, s *State, cmd Command) error {
+	err := s.Db.DeleteUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to delete users: %w", err)
 	}
@@ -121,72 +100,347 @@ func ResetHandler(s *State, cmd Command) error {
 	return nil
 }
 
-func AggregateFeedHandler(s *State, cmd Command) error {
-	if len(cmd.Arguments) != 1 {
-		return fmt.Errorf("incorrect command usage. use: %s <timeBetweenRequests>", cmd.Name)
+func MigrateHandler(ctx context.Context, s *State, cmd Command) error {
+	driver, _, err := s.Config.Driver()
+	if err != nil {
+		return fmt.Errorf("failed to get db driver: %w", err)
+	}
+	switch cmd.Arguments[0] {
+	case "up":
+		results, err := migrate.Up(ctx, s.Conn, driver)
+		for _, result := range results {
+			fmt.Fprintf(s.Out, "* %s %s (%v)\n", result.Direction, result.Source.Path, result.Duration.Round(time.Millisecond))
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Migrate: applied %v migrations", len(results))
+	case "down":
+		result, err := migrate.Down(ctx, s.Conn, driver)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(s.Out, "* %s %s (%v)\n", result.Direction, result.Source.Path, result.Duration.Round(time.Millisecond))
+		log.Printf("Migrate: rolled back version %v", result.Source.Version)
+	case "status":
+		statuses, err := migrate.Status(ctx, s.Conn, driver)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			if status.State == goose.StateApplied {
+				fmt.Fprintf(s.Out, "* %s applied at %v\n", status.Source.Path, status.AppliedAt.Format(time.DateTime))
+			} else {
+				fmt.Fprintf(s.Out, "* %s %s\n", status.Source.Path, status.State)
+			}
+		}
+	case "version":
+		current, latest, err := migrate.Versions(ctx, s.Conn, driver)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(s.Out, "version %d (latest %d)\n", current, latest)
+	default:
+		return fmt.Errorf("unknown migrate action '%s', must be up, down, status or version", cmd.Arguments[0])
 	}
+	return nil
+}
+
+func AggregateFeedHandler(ctx context.Context, s *State, cmd Command) error {
 	timeBetweenRequests, err := time.ParseDuration(cmd.Arguments[0])
 	if err != nil {
 		return fmt.Errorf("failed to parse duration from argument: %w", err)
 	}
-	log.Printf("Aggregate Feed: collecting feeds every %v\n", timeBetweenRequests)
-	ticker := time.NewTicker(timeBetweenRequests)
-	for ; ; <-ticker.C {
-		if err = scrapeFeeds(s); err != nil {
-			return fmt.Errorf("failed to scrape feeds: %w", err)
+	workers, maxPerHost := cmd.IntFlag("workers"), cmd.IntFlag("max-per-host")
+	if workers < 1 || maxPerHost < 1 {
+		return fmt.Errorf("--workers and --max-per-host must be positive")
+	}
+	log.Printf("Aggregate Feed: collecting feeds every %v with %d workers (max %d per host)\n", timeBetweenRequests, workers, maxPerHost)
+	hosts := newHostLimiter(maxPerHost)
+	stats := &aggStats{}
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(timeBetweenRequests)
+			defer ticker.Stop()
+			for {
+				if err := scrapeFeeds(ctx, s, hosts, stats); err != nil {
+					log.Printf("failed to scrape feeds: %s\n", err.Error())
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	<-ctx.Done()
+	log.Printf("Aggregate Feed: shutting down, waiting up to %v for in-flight feeds\n", aggShutdownGracePeriod)
+	wg.Wait()
+	log.Printf("Aggregate Feed: %s\n", stats)
+	return nil
+}
+
+// aggStats counts what the aggregator did, for the summary logged on shutdown.
+type aggStats struct {
+	fetched     atomic.Int64
+	notModified atomic.Int64
+	failed      atomic.Int64
+	posts       atomic.Int64
+	dropped     atomic.Int64
+}
+
+func (a *aggStats) String() string {
+	return fmt.Sprintf("%d feeds fetched, %d not modified, %d failed; %d posts stored, %d dropped",
+		a.fetched.Load(), a.notModified.Load(), a.failed.Load(), a.posts.Load(), a.dropped.Load())
+}
+
+// graceContext returns a context that is only cancelled once the grace period has elapsed after ctx is done,
+// so work that has already started can finish.
+func graceContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	graceCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(grace, cancel)
+	})
+	return graceCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// scrapeFeeds keeps claiming and scraping feeds until none is due or ctx is done.
+func scrapeFeeds(ctx context.Context, s *State, hosts *hostLimiter, stats *aggStats) error {
+	for ctx.Err() == nil {
+		feed, err := s.Db.ClaimNextFeedToFetch(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			// No feed is due yet
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to claim next feed to fetch: %w", err)
+		}
+		if err := scrapeFeed(ctx, s, feed, hosts, stats); err != nil {
+			log.Printf("failed to scrape feed: %s\n", err.Error())
 		}
 	}
+	return nil
 }
 
-func scrapeFeeds(s *State) error {
-	feed, err := s.Db.GetNextFeedToFetch(context.Background())
+func scrapeFeed(ctx context.Context, s *State, feed database.Feed, hosts *hostLimiter, stats *aggStats) error {
+	ctx, cancel := graceContext(ctx, aggShutdownGracePeriod)
+	defer cancel()
+	release, err := hosts.acquire(ctx, feed.Url)
 	if err != nil {
-		return fmt.Errorf("failed to get next feed to fetch: %w", err)
+		return fmt.Errorf("failed to wait for host: %w", err)
 	}
-	fetchedFeed, err := rss.FetchFeed(context.Background(), feed.Url)
-	if err != nil {
-		return fmt.Errorf("failed to fetch feed: %w", err)
+	defer release()
+	// schedules are stored and compared in UTC, whatever the aggregator's time zone
+	fetchedAt := time.Now().UTC()
+	validators := rss.Validators{
+		ETag:         feed.Etag.String,
+		LastModified: feed.LastModified.String,
 	}
-	if err = s.Db.MarkFeedFetched(context.Background(), feed.ID); err != nil {
-		return fmt.Errorf("failed to mark feed as fetched: %w", err)
+	fetchedFeed, info, err := rss.FetchFeed(ctx, feed.Url, validators)
+	if errors.Is(err, rss.ErrNotModified) {
+		stats.notModified.Add(1)
+		log.Printf("Not Modified: %s\n", feed.Name)
+		return markFeedFetched(ctx, s.Db, feed, fetchedAt, nil, info)
 	}
-	log.Printf("Fetched: %s (%v items)\n", feed.Name, len(fetchedFeed.Channel.Items))
-	for i, item := range fetchedFeed.Channel.Items {
-		pubDate, err := time.Parse(time.RFC1123Z, item.PubDate)
-		if err != nil {
-			continue
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to fetch feed '%s': %w", feed.Name, ctx.Err())
+	}
+	if err != nil {
+		stats.failed.Add(1)
+		return markFeedFailed(ctx, s.Db, feed, fetchedAt, info, err)
+	}
+	var posts []database.Post
+	dropped := 0
+	err = s.Db.InTx(ctx, func(q storage.Store) error {
+		posts, dropped = nil, 0
+		for _, item := range fetchedFeed.Channel.Items {
+			pubDate, err := item.PublishedAt(fetchedAt)
+			if err != nil {
+				dropped++
+				log.Printf("Dropped: '%s' from %s: %s\n", item.Title, feed.Name, err.Error())
+				continue
+			}
+			if item.ID() == "" {
+				dropped++
+				log.Printf("Dropped: '%s' from %s: missing guid and link\n", item.Title, feed.Name)
+				continue
+			}
+			if item.ID() != item.Link && item.Link != "" {
+				// posts stored before guids were keyed by url, so adopt them rather than duplicate them
+				_, err := q.RekeyLegacyPost(ctx, database.RekeyLegacyPostParams{
+					Guid:   item.ID(),
+					FeedID: feed.ID,
+					Url:    item.Link,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to rekey legacy post: %w", err)
+				}
+			}
+			params := database.UpsertPostParams{
+				FeedID:      feed.ID,
+				Title:       item.Title,
+				Url:         item.Link,
+				Description: item.Description,
+				PublishedAt: pubDate,
+				Guid:        item.ID(),
+			}
+			post, err := q.UpsertPost(ctx, params)
+			if errors.Is(err, sql.ErrNoRows) {
+				// The post is already stored and unchanged upstream
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to upsert post: %w", err)
+			}
+			posts = append(posts, post)
 		}
-		params := database.CreatePostParams{
-			FeedID:      feed.ID,
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: item.Description,
-			PublishedAt: pubDate,
+		return markFeedFetched(ctx, q, feed, fetchedAt, fetchedFeed, info)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to store feed '%s': %w", feed.Name, err)
 		}
-		post, err := s.Db.CreatePost(context.Background(), params)
-		if err != nil {
-			return fmt.Errorf("failed to create post: %w", err)
+		stats.failed.Add(1)
+		if markErr := markFeedFailed(ctx, s.Db, feed, fetchedAt, info, err); markErr != nil {
+			return markErr
 		}
-		fmt.Printf("\t%d. %s\n", i, post.Title)
-		fmt.Printf("\t\t %s\n", item.Description)
+		return fmt.Errorf("failed to store feed '%s': %w", feed.Name, err)
+	}
+	stats.fetched.Add(1)
+	stats.posts.Add(int64(len(posts)))
+	stats.dropped.Add(int64(dropped))
+	for i, post := range posts {
+		fmt.Fprintf(s.Out, "\t%d. %s\n", i, post.Title)
+		fmt.Fprintf(s.Out, "\t\t %s\n", post.Description)
 	}
+	log.Printf("Fetched: %s (%v items, %v dropped)\n", feed.Name, len(fetchedFeed.Channel.Items), dropped)
 	return nil
 }
 
-func AddFeedHandler(s *State, cmd Command, user database.User) error {
-	if len(cmd.Arguments) != 2 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s <feedName> <feedUrl>", cmd.Name)
+// markFeedFetched stores the response validators and schedules the feed's next fetch.
+func markFeedFetched(ctx context.Context, q storage.Store, feed database.Feed, fetchedAt time.Time, fetchedFeed *rss.RSSFeed, info rss.FetchInfo) error {
+	published, err := q.GetRecentPublishedDates(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get recent published dates: %w", err)
 	}
-	feedName, feedUrl := cmd.Arguments[0], cmd.Arguments[1]
-	feedParams := database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      feedName,
-		Url:       feedUrl,
-		UserID:    user.ID,
+	nextFetchAt := rss.NextFetch(fetchedAt, published, fetchedFeed, info)
+	params := database.MarkFeedFetchedParams{
+		ID:           feed.ID,
+		Etag:         nullString(info.ETag),
+		LastModified: nullString(info.LastModified),
+		NextFetchAt:  sql.NullTime{Time: nextFetchAt, Valid: true},
+		LastStatus:   nullInt32(info.StatusCode),
+	}
+	if err = q.MarkFeedFetched(ctx, params); err != nil {
+		return fmt.Errorf("failed to mark feed as fetched: %w", err)
+	}
+	log.Printf("Scheduled: %s next fetch at %v\n", feed.Name, nextFetchAt.Format(time.DateTime))
+	return nil
+}
+
+// markFeedFailed records a failed fetch and backs the feed off exponentially, disabling it after maxFeedFailures.
+func markFeedFailed(ctx context.Context, q storage.Store, feed database.Feed, fetchedAt time.Time, info rss.FetchInfo, fetchErr error) error {
+	failures := int(feed.ConsecutiveFailures) + 1
+	nextFetchAt := fetchedAt.Add(rss.Backoff(failures, info))
+	params := database.MarkFeedFailedParams{
+		ID:          feed.ID,
+		LastError:   nullString(fetchErr.Error()),
+		LastStatus:  nullInt32(info.StatusCode),
+		NextFetchAt: sql.NullTime{Time: nextFetchAt, Valid: true},
+	}
+	if failures >= maxFeedFailures {
+		params.DisabledAt = sql.NullTime{Time: fetchedAt, Valid: true}
+	}
+	if err := q.MarkFeedFailed(ctx, params); err != nil {
+		return fmt.Errorf("failed to mark feed as failed: %w", err)
+	}
+	if params.DisabledAt.Valid {
+		log.Printf("Disabled: %s after %d consecutive failures: %s\n", feed.Name, failures, fetchErr.Error())
+	} else {
+		log.Printf("Failed: %s (%d consecutive failures), retrying at %v: %s\n", feed.Name, failures, nextFetchAt.Format(time.DateTime), fetchErr.Error())
+	}
+	return nil
+}
+
+// parseTimeFlag parses an absolute date or a duration such as 24h or 7d, taken as that long before now.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := rss.ParseDate(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s': %w", value, err)
+	}
+	return t, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt32(i int) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(i), Valid: i != 0}
+}
+
+func AddFeedHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	var feedName, pageUrl string
+	if len(cmd.Arguments) == 2 {
+		feedName, pageUrl = cmd.Arguments[0], cmd.Arguments[1]
+	} else {
+		pageUrl = cmd.Arguments[0]
+	}
+	discovered, err := rss.DiscoverFeeds(ctx, pageUrl)
+	if err != nil {
+		return fmt.Errorf("failed to discover feeds: %w", err)
+	}
+	if len(discovered) == 0 {
+		return fmt.Errorf("no feed found at '%s'", pageUrl)
 	}
-	feed, err := s.Db.CreateFeed(context.Background(), feedParams)
+	chosen, err := chooseFeed(discovered, s.In, s.Out)
+	if err != nil {
+		return fmt.Errorf("failed to choose feed: %w", err)
+	}
+	feedUrl := chosen.URL
+	if feedUrl != pageUrl {
+		log.Printf("Add Feed: discovered '%s' at %s", chosen.Title, feedUrl)
+	}
+	fetchedFeed := chosen.Feed
+	if fetchedFeed == nil {
+		if fetchedFeed, _, err = rss.FetchFeed(ctx, feedUrl, rss.Validators{}); err != nil {
+			return fmt.Errorf("failed to fetch feed: %w", err)
+		}
+	}
+	channel := fetchedFeed.Channel
+	title := strings.TrimSpace(channel.Title)
+	if feedName == "" {
+		feedName = cmp.Or(title, feedUrl)
+	}
+	feedParams := database.CreateFeedParams{
+		ID:          uuid.New(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Name:        feedName,
+		Url:         feedUrl,
+		UserID:      user.ID,
+		Title:       nullString(title),
+		Description: nullString(strings.TrimSpace(channel.Description)),
+		SiteUrl:     nullString(strings.TrimSpace(channel.Link)),
+		Language:    nullString(strings.TrimSpace(channel.Language)),
+		IconUrl:     nullString(strings.TrimSpace(channel.Image.URL)),
+	}
+	feed, err := s.Db.CreateFeed(ctx, feedParams)
 	if err != nil {
 		return fmt.Errorf("failed to create feed: %w", err)
 	}
@@ -197,50 +451,101 @@ func AddFeedHandler(s *State, cmd Command, user database.User) error {
 		UserID:    user.ID,
 		FeedID:    feed.ID,
 	}
-	_, err = s.Db.CreateFeedFollow(context.Background(), feedFollowParams)
+	_, err = s.Db.CreateFeedFollow(ctx, feedFollowParams)
 	if err != nil {
 		return fmt.Errorf("failed to follow feed: %w", err)
 	}
-	fmt.Println(feed)
+	fmt.Fprintln(s.Out, feed)
 	log.Printf("Add Feed: '%s' added '%s' (%s)", user.Name, feedName, feedUrl)
 	return nil
 }
 
-func DeleteFeedHandler(s *State, cmd Command) error {
-	if len(cmd.Arguments) != 1 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s <feedUrl>", cmd.Name)
+// chooseFeed picks the only discovered feed, or asks the user to choose one when there are several.
+func chooseFeed(feeds []rss.DiscoveredFeed, in io.Reader, out io.Writer) (rss.DiscoveredFeed, error) {
+	if len(feeds) == 1 {
+		return feeds[0], nil
+	}
+	fmt.Fprintln(out, "Found several feeds:")
+	for i, feed := range feeds {
+		fmt.Fprintf(out, "  %d) %s (%s)\n", i+1, feed.Title, feed.URL)
+	}
+	fmt.Fprintf(out, "Choose a feed [1-%d]: ", len(feeds))
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return rss.DiscoveredFeed{}, err
 	}
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(feeds) {
+		return rss.DiscoveredFeed{}, fmt.Errorf("invalid choice '%s'", strings.TrimSpace(line))
+	}
+	return feeds[choice-1], nil
+}
+
+func DeleteFeedHandler(ctx context.Context, s *State, cmd Command) error {
 	feedUrl := cmd.Arguments[0]
-	err := s.Db.DeleteFeed(context.Background(), feedUrl)
+	deleted, err := s.Db.DeleteFeed(ctx, feedUrl)
 	if err != nil {
 		return fmt.Errorf("failed to delete feed: %w", err)
 	}
+	if deleted == 0 {
+		return fmt.Errorf("feed '%s' not found", feedUrl)
+	}
 	log.Printf("Delete Feed: %s", feedUrl)
 	return nil
 }
 
-func FeedsHandler(s *State, cmd Command) error {
-	if len(cmd.Arguments) != 0 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s", cmd.Name)
+func EnableFeedHandler(ctx context.Context, s *State, cmd Command) error {
+	feedUrl := cmd.Arguments[0]
+	enabled, err := s.Db.EnableFeed(ctx, feedUrl)
+	if err != nil {
+		return fmt.Errorf("failed to enable feed: %w", err)
+	}
+	if enabled == 0 {
+		return fmt.Errorf("feed '%s' not found", feedUrl)
 	}
+	log.Printf("Enable Feed: %s", feedUrl)
+	return nil
+}
 
-	feeds, err := s.Db.GetUserFeeds(context.Background())
+func FeedsHandler(ctx context.Context, s *State, cmd Command) error {
+	feeds, err := s.Db.GetUserFeeds(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get all feeds: %w", err)
 	}
 	for _, feed := range feeds {
-		fmt.Printf("* %s(%s) from %s\n", feed.Name, feed.Url, feed.UserName)
+		switch {
+		case feed.DisabledAt.Valid:
+			fmt.Fprintf(s.Out, "* %s(%s) from %s [disabled: %s]\n", feed.Name, feed.Url, feed.UserName, feed.LastError.String)
+		case feed.ConsecutiveFailures > 0:
+			fmt.Fprintf(s.Out, "* %s(%s) from %s [%d failures: %s]\n", feed.Name, feed.Url, feed.UserName, feed.ConsecutiveFailures, feed.LastError.String)
+		default:
+			fmt.Fprintf(s.Out, "* %s(%s) from %s\n", feed.Name, feed.Url, feed.UserName)
+		}
+		printFeedDetails(s.Out, feed.Name, feed.Title, feed.SiteUrl, feed.Language, feed.Description)
 	}
 	log.Printf("Feeds: %v feeds", len(feeds))
 	return nil
 }
 
-func FollowFeedsHandler(s *State, cmd Command, user database.User) error {
-	if len(cmd.Arguments) != 1 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s <feedUrl>", cmd.Name)
+// printFeedDetails prints the metadata a feed describes itself with, leaving out its title when it's already the feed's name.
+func printFeedDetails(out io.Writer, name string, title, siteUrl, language, description sql.NullString) {
+	var details []string
+	if title.String != name {
+		details = append(details, title.String)
 	}
+	details = append(details, siteUrl.String, language.String)
+	details = slices.DeleteFunc(details, func(detail string) bool { return detail == "" })
+	if len(details) > 0 {
+		fmt.Fprintf(out, "    %s\n", strings.Join(details, " | "))
+	}
+	if description.String != "" {
+		fmt.Fprintf(out, "    %s\n", description.String)
+	}
+}
+
+func FollowFeedsHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
 	feedUrl := cmd.Arguments[0]
-	feed, err := s.Db.GetFeed(context.Background(), feedUrl)
+	feed, err := s.Db.GetFeed(ctx, feedUrl)
 	if err != nil {
 		return fmt.Errorf("failed to get feed: %w", err)
 	}
@@ -251,7 +556,7 @@ func FollowFeedsHandler(s *State, cmd Command, user database.User) error {
 		UserID:    user.ID,
 		FeedID:    feed.ID,
 	}
-	feed_follow, err := s.Db.CreateFeedFollow(context.Background(), feedFollowParams)
+	feed_follow, err := s.Db.CreateFeedFollow(ctx, feedFollowParams)
 	if err != nil {
 		return fmt.Errorf("failed to follow feed: %w", err)
 	}
@@ -259,31 +564,26 @@ func FollowFeedsHandler(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
-func FollowedFeedsHandler(s *State, cmd Command, user database.User) error {
-	if len(cmd.Arguments) != 0 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s", cmd.Name)
-	}
-	feeds, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+func FollowedFeedsHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	feeds, err := s.Db.GetFeedFollowsForUser(ctx, user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get followed feeds: %w", err)
 	}
 	for _, feed := range feeds {
-		fmt.Printf("* %s follows %s\n", user.Name, feed.FeedName)
+		fmt.Fprintf(s.Out, "* %s follows %s\n", user.Name, feed.FeedName)
+		printFeedDetails(s.Out, feed.FeedName, feed.FeedTitle, feed.FeedSiteUrl, sql.NullString{}, sql.NullString{})
 	}
 	log.Printf("Follows: %s follows %v feeds\n", user.Name, len(feeds))
 	return nil
 }
 
-func UnFollowFeedHandler(s *State, cmd Command, user database.User) error {
-	if len(cmd.Arguments) != 1 {
-		return fmt.Errorf("incorrect command usage.\nusage: %s <feedUrl>", cmd.Name)
-	}
+func UnFollowFeedHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
 	feedUrl := cmd.Arguments[0]
 	params := database.DeleteFeedFollowParams{
 		UserID: user.ID,
 		Url:    feedUrl,
 	}
-	_, err := s.Db.DeleteFeedFollow(context.Background(), params)
+	_, err := s.Db.DeleteFeedFollow(ctx, params)
 	if err != nil {
 		return fmt.Errorf("failed to delete feed: %w", err)
 	}
@@ -291,25 +591,236 @@ func UnFollowFeedHandler(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
-func BrowsePostsHandler(s *State, cmd Command, user database.User) error {
-	if len(cmd.Arguments) > 1 {
-		return fmt.Errorf("incorrect command usage. use: %s [limit]", cmd.Name)
+func BrowsePostsHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	limit := cmd.IntFlag("limit")
+	// browse used to take the limit as its only argument
+	if len(cmd.Arguments) == 1 {
+		var err error
+		if limit, err = strconv.Atoi(cmd.Arguments[0]); err != nil {
+			return fmt.Errorf("failed to parse limit from argument: %w", err)
+		}
+	}
+	pageNumber := cmd.IntFlag("page")
+	after := cmd.StringFlag("after")
+	feedUrl := cmd.StringFlag("feed")
+	sort := cmd.StringFlag("sort")
+	if limit < 1 || pageNumber < 1 {
+		return fmt.Errorf("--limit and --page must be positive")
+	}
+	if sort != "asc" && sort != "desc" {
+		return fmt.Errorf("invalid --sort '%s', must be asc or desc", sort)
 	}
 	params := database.GetPostsFromUserParams{
-		UserID: user.ID,
+		UserID:      user.ID,
+		IncludeRead: cmd.BoolFlag("all"),
+		OldestFirst: sort == "asc",
+		Limit:       int32(limit),
+		Offset:      int32((pageNumber - 1) * limit),
+	}
+	if feedUrl != "" {
+		feed, err := s.Db.GetFeed(ctx, feedUrl)
+		if err != nil {
+			return fmt.Errorf("failed to get feed: %w", err)
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	now := time.Now()
+	for _, bound := range []struct {
+		value string
+		param *sql.NullTime
+	}{{cmd.StringFlag("since"), &params.Since}, {cmd.StringFlag("until"), &params.Until}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := parseTimeFlag(bound.value, now)
+		if err != nil {
+			return err
+		}
+		*bound.param = sql.NullTime{Time: t, Valid: true}
 	}
-	if len(cmd.Arguments) == 0 {
-		params.Limit = defaultBrowseLimit
+	if after != "" {
+		postID, err := uuid.Parse(after)
+		if err != nil {
+			return fmt.Errorf("invalid post id '%s': %w", after, err)
+		}
+		post, err := s.Db.GetPost(ctx, postID)
+		if err != nil {
+			return fmt.Errorf("failed to get post: %w", err)
+		}
+		params.AfterPublishedAt = sql.NullTime{Time: post.PublishedAt, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: post.ID, Valid: true}
 	}
-	posts, err := s.Db.GetPostsFromUser(context.Background(), params)
+	posts, err := s.Db.GetPostsFromUser(ctx, params)
 	if err != nil {
 		return fmt.Errorf("failed to get posts from user: %w", err)
 	}
 	for _, post := range posts {
-		fmt.Printf("%s (%v)\n", post.Title, post.PublishedAt.Format(time.DateTime))
-		fmt.Println("-----------------------------------------")
-		fmt.Printf("%v\n", post.Description)
-		fmt.Println("=========================================")
+		if post.ReadAt.Valid {
+			fmt.Fprintf(s.Out, "%s (%v) [read]\n", post.Title, post.PublishedAt.Format(time.DateTime))
+		} else {
+			fmt.Fprintf(s.Out, "%s (%v)\n", post.Title, post.PublishedAt.Format(time.DateTime))
+		}
+		fmt.Fprintf(s.Out, "id: %s\n", post.ID)
+		fmt.Fprintln(s.Out, "-----------------------------------------")
+		fmt.Fprintf(s.Out, "%v\n", post.Description)
+		fmt.Fprintln(s.Out, "=========================================")
+	}
+	if len(posts) == limit {
+		log.Printf("Browse: %v posts, continue with --after %s\n", len(posts), posts[len(posts)-1].ID)
+	} else {
+		log.Printf("Browse: %v posts\n", len(posts))
+	}
+	return nil
+}
+
+func SearchPostsHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	// the shell strips the quotes around phrases, so put them back
+	terms := make([]string, 0, len(cmd.Arguments))
+	for _, arg := range cmd.Arguments {
+		if strings.ContainsFunc(arg, unicode.IsSpace) && !strings.Contains(arg, `"`) {
+			negated := strings.HasPrefix(arg, "-")
+			arg = `"` + strings.TrimPrefix(arg, "-") + `"`
+			if negated {
+				arg = "-" + arg
+			}
+		}
+		terms = append(terms, arg)
+	}
+	params := database.SearchPostsParams{
+		Query:  strings.Join(terms, " "),
+		UserID: user.ID,
+		Limit:  defaultSearchLimit,
+	}
+	posts, err := s.Db.SearchPosts(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to search posts: %w", err)
+	}
+	for _, post := range posts {
+		fmt.Fprintf(s.Out, "%s (%v) from %s\n", post.TitleSnippet, post.PublishedAt.Format(time.DateTime), post.FeedName)
+		fmt.Fprintf(s.Out, "id: %s\n", post.ID)
+		fmt.Fprintf(s.Out, "url: %s\n", post.Url)
+		fmt.Fprintln(s.Out, "-----------------------------------------")
+		fmt.Fprintf(s.Out, "%v\n", post.Snippet)
+		fmt.Fprintln(s.Out, "=========================================")
+	}
+	log.Printf("Search: %v posts match '%s'\n", len(posts), params.Query)
+	return nil
+}
+
+func ReadPostHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	postID, err := uuid.Parse(cmd.Arguments[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id '%s': %w", cmd.Arguments[0], err)
+	}
+	params := database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: postID,
+	}
+	if _, err = s.Db.MarkPostRead(ctx, params); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("post '%s' not found in followed feeds", postID)
+		}
+		return fmt.Errorf("failed to mark post as read: %w", err)
+	}
+	log.Printf("Read: '%s' read post %s\n", user.Name, postID)
+	return nil
+}
+
+func UnreadPostHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	postID, err := uuid.Parse(cmd.Arguments[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id '%s': %w", cmd.Arguments[0], err)
+	}
+	params := database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: postID,
+	}
+	if err = s.Db.MarkPostUnread(ctx, params); err != nil {
+		return fmt.Errorf("failed to mark post as unread: %w", err)
+	}
+	log.Printf("Unread: '%s' marked post %s as unread\n", user.Name, postID)
+	return nil
+}
+
+func MarkAllReadHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	if len(cmd.Arguments) == 0 {
+		marked, err := s.Db.MarkAllPostsRead(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to mark posts as read: %w", err)
+		}
+		log.Printf("Read: '%s' marked %v posts as read\n", user.Name, marked)
+		return nil
+	}
+	feedUrl := cmd.Arguments[0]
+	feed, err := s.Db.GetFeed(ctx, feedUrl)
+	if err != nil {
+		return fmt.Errorf("failed to get feed: %w", err)
+	}
+	params := database.MarkFeedPostsReadParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	}
+	marked, err := s.Db.MarkFeedPostsRead(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to mark posts as read: %w", err)
+	}
+	log.Printf("Read: '%s' marked %v posts from '%s' as read\n", user.Name, marked, feed.Name)
+	return nil
+}
+
+func BookmarkPostHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	postID, err := uuid.Parse(cmd.Arguments[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id '%s': %w", cmd.Arguments[0], err)
+	}
+	params := database.CreateBookmarkParams{
+		UserID: user.ID,
+		PostID: postID,
+	}
+	bookmark, err := s.Db.CreateBookmark(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("post '%s' not found in followed feeds", postID)
+		}
+		return fmt.Errorf("failed to bookmark post: %w", err)
+	}
+	log.Printf("Bookmark: '%s' bookmarked '%s'\n", user.Name, bookmark.Title)
+	return nil
+}
+
+func UnbookmarkPostHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	postID, err := uuid.Parse(cmd.Arguments[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id '%s': %w", cmd.Arguments[0], err)
+	}
+	params := database.DeleteBookmarkParams{
+		UserID: user.ID,
+		PostID: postID,
+	}
+	deleted, err := s.Db.DeleteBookmark(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to delete bookmark: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("post '%s' is not bookmarked", postID)
+	}
+	log.Printf("Unbookmark: '%s' unbookmarked post %s\n", user.Name, postID)
+	return nil
+}
+
+func BookmarksHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	bookmarks, err := s.Db.GetBookmarksForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+	for _, bookmark := range bookmarks {
+		fmt.Fprintf(s.Out, "%s (%v) from %s\n", bookmark.Title, bookmark.PublishedAt.Format(time.DateTime), bookmark.FeedName)
+		fmt.Fprintf(s.Out, "id: %s\n", bookmark.PostID)
+		fmt.Fprintf(s.Out, "url: %s\n", bookmark.Url)
+		fmt.Fprintln(s.Out, "-----------------------------------------")
+		fmt.Fprintf(s.Out, "%v\n", bookmark.Description)
+		fmt.Fprintln(s.Out, "=========================================")
 	}
+	log.Printf("Bookmarks: %s has %v bookmarks\n", user.Name, len(bookmarks))
 	return nil
 }
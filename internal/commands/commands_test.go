@@ -0,0 +1,611 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charlesaraya/gator/internal/config"
+	"github.com/charlesaraya/gator/internal/database"
+	"github.com/charlesaraya/gator/internal/migrate"
+	"github.com/charlesaraya/gator/internal/storage"
+)
+
+const (
+	techFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<title>Tech Blog</title>
+<link>https://tech.example.com/</link>
+<description>Posts about programming</description>
+<item><title>Go 1.23 released</title><link>https://tech.example.com/go-1-23</link><description>Range over func arrives in Go</description><pubDate>Tue, 13 Aug 2024 10:00:00 GMT</pubDate></item>
+<item><title>SQLite tips</title><link>https://tech.example.com/sqlite</link><description>Indexes and the query planner</description><pubDate>Sat, 10 Aug 2024 10:00:00 GMT</pubDate></item>
+<item><title>Rust or Go</title><link>https://tech.example.com/rust-or-go</link><description>Picking a systems language</description><pubDate>Thu, 01 Aug 2024 10:00:00 GMT</pubDate></item>
+</channel></rss>`
+	newsFeed = `<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Daily News</title>
+<link href="https://news.example.com/"/>
+<entry><title>Election results</title><id>tag:news.example.com,2024:1</id><link href="https://news.example.com/election"/><updated>2024-08-12T08:00:00Z</updated><summary>Who won</summary></entry>
+<entry><title>Weather warning</title><id>tag:news.example.com,2024:2</id><link href="https://news.example.com/weather"/><updated>2024-08-05T08:00:00Z</updated><summary>Storms ahead</summary></entry>
+</feed>`
+	// invalidFeed has items agg would drop and items whose guids collide.
+	invalidFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<title>Invalid</title>
+<item><title>Undated</title><guid>1</guid><pubDate>someday</pubDate></item>
+<item><title>Anonymous</title></item>
+<item><title>First</title><guid>2</guid></item>
+<item><title>Second</title><guid>2</guid></item>
+</channel></rss>`
+	sitePage = `<html><head>
+<link rel="alternate" type="application/rss+xml" title="Tech Blog" href="/tech.xml">
+<link rel="alternate" type="application/atom+xml" title="Daily News" href="/news.xml">
+</head><body></body></html>`
+)
+
+// newFeedServer serves the test feeds, and a web page advertising both of them at /site.
+func newFeedServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	for path, body := range map[string]string{"/tech.xml": techFeed, "/news.xml": newsFeed, "/invalid.xml": invalidFeed, "/site": sitePage, "/empty": "<html></html>"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasPrefix(body, "<?xml"):
+				w.Header().Set("Content-Type", "application/xml")
+			default:
+				w.Header().Set("Content-Type", "text/html")
+			}
+			fmt.Fprint(w, body)
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testEnv runs commands against an in-memory store, capturing their output.
+type testEnv struct {
+	t    *testing.T
+	ctx  context.Context
+	s    *State
+	out  *bytes.Buffer
+	cmds Commands
+	// vars are substituted for their {name} in the arguments of steps.
+	vars map[string]string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Setenv("HOME", t.TempDir())
+	out := &bytes.Buffer{}
+	return &testEnv{
+		t:   t,
+		ctx: context.Background(),
+		s: &State{
+			Config: &config.Config{},
+			Db:     storage.NewMemory(),
+			In:     strings.NewReader(""),
+			Out:    out,
+		},
+		out:  out,
+		cmds: GetCommands(),
+		vars: make(map[string]string),
+	}
+}
+
+// newSeededEnv returns an env where kahya follows the tech and news feeds, whose posts have been scraped.
+func newSeededEnv(t *testing.T) *testEnv {
+	env := newTestEnv(t)
+	seedEnv(env)
+	return env
+}
+
+// seedEnv has kahya follow the tech and news feeds in env's store, and scrapes their posts.
+func seedEnv(env *testEnv) {
+	t := env.t
+	server := newFeedServer(t)
+	env.vars["tech"] = server.URL + "/tech.xml"
+	env.vars["news"] = server.URL + "/news.xml"
+	env.mustRun("register", "kahya")
+	env.mustRun("addfeed", env.vars["tech"])
+	env.mustRun("addfeed", env.vars["news"])
+	if err := scrapeFeeds(context.Background(), env.s, newHostLimiter(1), &aggStats{}); err != nil {
+		t.Fatalf("scrapeFeeds returned error: %v", err)
+	}
+	user, err := env.s.Db.GetUser(context.Background(), "kahya")
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts, err := env.s.Db.GetPostsFromUser(context.Background(), database.GetPostsFromUserParams{UserID: user.ID, IncludeRead: true, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 5 {
+		t.Fatalf("scraped %d posts, want 5", len(posts))
+	}
+	for _, post := range posts {
+		env.vars[strings.Fields(post.Title)[0]] = post.ID.String()
+	}
+}
+
+func (env *testEnv) run(input string, args ...string) (string, error) {
+	env.out.Reset()
+	env.s.In = strings.NewReader(input)
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = env.expand(arg)
+	}
+	err := env.cmds.Run(env.ctx, env.s, Command{Name: expanded[0], Arguments: expanded[1:]})
+	return env.out.String(), err
+}
+
+func (env *testEnv) expand(s string) string {
+	for name, value := range env.vars {
+		s = strings.ReplaceAll(s, "{"+name+"}", value)
+	}
+	return s
+}
+
+func (env *testEnv) mustRun(args ...string) string {
+	env.t.Helper()
+	out, err := env.run("", args...)
+	if err != nil {
+		env.t.Fatalf("%s returned error: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+// step is a command run as part of a test, and what it should print or fail with.
+type step struct {
+	args  []string
+	input string
+	// want must appear in the output in this order.
+	want    []string
+	notWant []string
+	wantErr string
+}
+
+func runSteps(t *testing.T, env *testEnv, steps []step) {
+	for _, st := range steps {
+		name := strings.Join(st.args, " ")
+		out, err := env.run(st.input, st.args...)
+		wantErr := env.expand(st.wantErr)
+		switch {
+		case st.wantErr == "" && err != nil:
+			t.Fatalf("%s returned error: %v", name, err)
+		case st.wantErr != "" && err == nil:
+			t.Fatalf("%s succeeded, want error containing '%s'", name, wantErr)
+		case st.wantErr != "" && !strings.Contains(err.Error(), wantErr):
+			t.Fatalf("%s returned error '%v', want error containing '%s'", name, err, wantErr)
+		}
+		rest := out
+		for _, want := range st.want {
+			want = env.expand(want)
+			i := strings.Index(rest, want)
+			if i < 0 {
+				t.Fatalf("%s printed:\n%s\nwant '%s' (after the previous expected output)", name, out, want)
+			}
+			rest = rest[i+len(want):]
+		}
+		for _, notWant := range st.notWant {
+			if notWant = env.expand(notWant); strings.Contains(out, notWant) {
+				t.Fatalf("%s printed:\n%s\nwant no '%s'", name, out, notWant)
+			}
+		}
+	}
+}
+
+func TestUserHandlers(t *testing.T) {
+	env := newTestEnv(t)
+	runSteps(t, env, []step{
+		{args: []string{"users"}, notWant: []string{"*"}},
+		{args: []string{"register", "kahya"}},
+		{args: []string{"users"}, want: []string{"* kahya (current)"}},
+		{args: []string{"register", "holly"}},
+		{args: []string{"users"}, want: []string{"* holly (current)"}},
+		{args: []string{"users"}, want: []string{"* kahya\n"}},
+		{args: []string{"register", "kahya"}, wantErr: "failed to create user"},
+		{args: []string{"login", "kahya"}},
+		{args: []string{"users"}, want: []string{"* holly\n"}},
+		{args: []string{"users"}, want: []string{"* kahya (current)"}},
+		{args: []string{"login", "nobody"}, wantErr: "failed to get user"},
+		{args: []string{"login"}, wantErr: "incorrect command usage"},
+		{args: []string{"reset"}},
+		{args: []string{"users"}, notWant: []string{"*"}},
+	})
+	data, err := os.ReadFile(filepath.Join(os.Getenv("HOME"), config.CONFIG_FILE))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"current_user_name":"kahya"`) {
+		t.Errorf("config file is %s, want kahya as the current user", data)
+	}
+}
+
+func TestFeedHandlers(t *testing.T) {
+	server := newFeedServer(t)
+	env := newTestEnv(t)
+	env.vars["server"] = server.URL
+	runSteps(t, env, []step{
+		{args: []string{"addfeed", "{server}/tech.xml"}, wantErr: "no rows in result set"},
+		{args: []string{"register", "kahya"}},
+		{args: []string{"addfeed", "{server}/tech.xml"}, want: []string{"Tech Blog", server.URL + "/tech.xml"}},
+		{args: []string{"addfeed", "{server}/tech.xml"}, wantErr: "failed to create feed"},
+		{args: []string{"addfeed", "{server}/empty"}, wantErr: "no feed found"},
+		{args: []string{"following"}, want: []string{"* kahya follows Tech Blog", "https://tech.example.com/"}},
+		{args: []string{"feeds"}, want: []string{"* Tech Blog(" + server.URL + "/tech.xml) from kahya", "Posts about programming"}},
+		{args: []string{"register", "holly"}},
+		{args: []string{"addfeed", "{server}/site"}, input: "3\n", wantErr: "invalid choice '3'"},
+		{args: []string{"addfeed", "Headlines", "{server}/site"}, input: "2\n",
+			want: []string{"Found several feeds:", "1) Tech Blog (" + server.URL + "/tech.xml)", "2) Daily News (" + server.URL + "/news.xml)", "Choose a feed [1-2]: ", "Headlines"}},
+		{args: []string{"follow", "{server}/tech.xml"}},
+		{args: []string{"follow", "{server}/tech.xml"}, wantErr: "failed to follow feed"},
+		{args: []string{"follow", "{server}/missing.xml"}, wantErr: "failed to get feed"},
+		{args: []string{"following"}, want: []string{"* holly follows Headlines", "Daily News", "* holly follows Tech Blog"}},
+		{args: []string{"unfollow", "{server}/news.xml"}},
+		{args: []string{"following"}, want: []string{"* holly follows Tech Blog"}, notWant: []string{"Headlines"}},
+		{args: []string{"login", "kahya"}},
+		{args: []string{"following"}, want: []string{"* kahya follows Tech Blog"}, notWant: []string{"Headlines"}},
+	})
+}
+
+func TestBrowseHandler(t *testing.T) {
+	env := newSeededEnv(t)
+	runSteps(t, env, []step{
+		{args: []string{"browse", "--limit", "10"}, want: []string{"Go 1.23 released (2024-08-13 10:00:00)", "id: {Go}", "Range over func arrives in Go",
+			"Election results", "SQLite tips", "Weather warning", "Rust or Go"}},
+		{args: []string{"browse"}, want: []string{"Go 1.23 released", "Election results"}, notWant: []string{"SQLite tips"}},
+		{args: []string{"browse", "3"}, want: []string{"Go 1.23 released", "Election results", "SQLite tips"}, notWant: []string{"Weather warning"}},
+		{args: []string{"browse", "--limit", "2", "--page", "2"}, want: []string{"SQLite tips", "Weather warning"},
+			notWant: []string{"Go 1.23 released", "Election results", "Rust or Go"}},
+		{args: []string{"browse", "--limit", "2", "--page", "4"}, notWant: []string{"id:"}},
+		{args: []string{"browse", "--limit", "2", "--page", "2", "--sort", "asc"}, want: []string{"SQLite tips", "Election results"},
+			notWant: []string{"Rust or Go", "Weather warning", "Go 1.23 released"}},
+		{args: []string{"browse", "--limit", "1", "--page", "2", "--after", "{Election}"}, want: []string{"Weather warning"},
+			notWant: []string{"SQLite tips", "Rust or Go"}},
+		{args: []string{"browse", "--after", "{Election}", "--limit", "10"}, want: []string{"SQLite tips", "Weather warning", "Rust or Go"},
+			notWant: []string{"Go 1.23 released", "Election results"}},
+		{args: []string{"browse", "--feed", "{news}"}, want: []string{"Election results", "Weather warning"}, notWant: []string{"Tech"}},
+		{args: []string{"browse", "--sort", "asc", "--limit", "10"}, want: []string{"Rust or Go", "Weather warning", "SQLite tips", "Election results", "Go 1.23 released"}},
+		{args: []string{"browse", "--since", "2024-08-10", "--until", "2024-08-13", "--limit", "10"}, want: []string{"Election results", "SQLite tips"},
+			notWant: []string{"Go 1.23 released", "Weather warning", "Rust or Go"}},
+		{args: []string{"browse", "--since", "1d"}, notWant: []string{"id:"}},
+		{args: []string{"browse", "--limit", "0"}, wantErr: "must be positive"},
+		{args: []string{"browse", "--page", "0"}, wantErr: "must be positive"},
+		{args: []string{"browse", "--unread"}, wantErr: "incorrect command usage"},
+		{args: []string{"browse", "--sort", "up"}, wantErr: "invalid --sort 'up'"},
+		{args: []string{"browse", "--since", "someday"}, wantErr: "invalid time 'someday'"},
+		{args: []string{"browse", "--after", "nope"}, wantErr: "invalid post id 'nope'"},
+		{args: []string{"browse", "--feed", "https://missing.example.com/feed"}, wantErr: "failed to get feed"},
+	})
+}
+
+// TestBrowsePages pages through posts in SQLite, where --page is an OFFSET in the query.
+func TestBrowsePages(t *testing.T) {
+	env := newTestEnv(t)
+	env.s.Db = openTestStore(t, "sqlite://"+filepath.Join(t.TempDir(), "gator.db"))
+	seedEnv(env)
+	runSteps(t, env, []step{
+		{args: []string{"browse", "--limit", "2", "--page", "2"}, want: []string{"SQLite tips", "Weather warning"},
+			notWant: []string{"Go 1.23 released", "Election results", "Rust or Go"}},
+		{args: []string{"browse", "--limit", "2", "--page", "3"}, want: []string{"Rust or Go"}, notWant: []string{"SQLite tips"}},
+		{args: []string{"browse", "--limit", "2", "--page", "4"}, notWant: []string{"id:"}},
+		{args: []string{"browse", "--limit", "1", "--page", "2", "--after", "{Weather}", "--sort", "asc"}, want: []string{"Election results"},
+			notWant: []string{"SQLite tips", "Go 1.23 released"}},
+	})
+}
+
+func TestReadHandlers(t *testing.T) {
+	env := newSeededEnv(t)
+	runSteps(t, env, []step{
+		{args: []string{"read", "{Go}"}},
+		{args: []string{"browse"}, want: []string{"Election results"}, notWant: []string{"Go 1.23 released"}},
+		{args: []string{"browse", "--all"}, want: []string{"Go 1.23 released (2024-08-13 10:00:00) [read]", "Election results (2024-08-12 08:00:00)\n"}},
+		{args: []string{"unread", "{Go}"}},
+		{args: []string{"browse"}, want: []string{"Go 1.23 released (2024-08-13 10:00:00)\n"}},
+		{args: []string{"mark-all-read", "{news}"}},
+		{args: []string{"browse", "--limit", "10"}, want: []string{"Go 1.23 released", "SQLite tips", "Rust or Go"}, notWant: []string{"Election results", "Weather warning"}},
+		{args: []string{"mark-all-read"}},
+		{args: []string{"browse"}, notWant: []string{"id:"}},
+		{args: []string{"browse", "--all", "--limit", "10"}, want: []string{"Go 1.23 released", "[read]", "Rust or Go", "[read]"}},
+		{args: []string{"read", "nope"}, wantErr: "invalid post id 'nope'"},
+		{args: []string{"read", "00000000-0000-0000-0000-000000000000"}, wantErr: "not found in followed feeds"},
+		{args: []string{"mark-all-read", "https://missing.example.com/feed"}, wantErr: "failed to get feed"},
+	})
+}
+
+func TestBookmarkHandlers(t *testing.T) {
+	env := newSeededEnv(t)
+	runSteps(t, env, []step{
+		{args: []string{"bookmarks"}, notWant: []string{"id:"}},
+		{args: []string{"bookmark", "{SQLite}"}},
+		{args: []string{"bookmark", "{Weather}"}},
+		{args: []string{"bookmarks"}, want: []string{"Weather warning (2024-08-05 08:00:00) from Daily News",
+			"SQLite tips (2024-08-10 10:00:00) from Tech Blog", "id: {SQLite}", "url: https://tech.example.com/sqlite", "Indexes and the query planner"}},
+		{args: []string{"unbookmark", "{SQLite}"}},
+		{args: []string{"bookmarks"}, want: []string{"Weather warning"}, notWant: []string{"SQLite tips"}},
+		{args: []string{"unbookmark", "{SQLite}"}, wantErr: "is not bookmarked"},
+		{args: []string{"bookmark", "nope"}, wantErr: "invalid post id 'nope'"},
+		{args: []string{"bookmark", "00000000-0000-0000-0000-000000000000"}, wantErr: "not found in followed feeds"},
+		{args: []string{"unfollow", "{news}"}},
+		{args: []string{"bookmark", "{Election}"}, wantErr: "not found in followed feeds"},
+	})
+}
+
+// TestBookmarksOutliveFeed deletes the feed of a bookmarked post and makes sure the bookmark is kept.
+func TestBookmarksOutliveFeed(t *testing.T) {
+	stores := map[string]func(t *testing.T) storage.Store{
+		"memory": func(t *testing.T) storage.Store { return storage.NewMemory() },
+		"sqlite": func(t *testing.T) storage.Store {
+			return openTestStore(t, "sqlite://"+filepath.Join(t.TempDir(), "gator.db"))
+		},
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.s.Db = open(t)
+			seedEnv(env)
+			runSteps(t, env, []step{
+				{args: []string{"bookmark", "{Weather}"}},
+				{args: []string{"delfeed", "{news}"}},
+				{args: []string{"browse", "--all", "--limit", "10"}, notWant: []string{"Weather warning"}},
+				{args: []string{"bookmarks"}, want: []string{"Weather warning (2024-08-05 08:00:00) from Daily News", "id: {Weather}",
+					"url: https://news.example.com/weather", "Storms ahead"}},
+				{args: []string{"unbookmark", "{Weather}"}},
+				{args: []string{"bookmarks"}, notWant: []string{"id:"}},
+			})
+		})
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	env := newSeededEnv(t)
+	runSteps(t, env, []step{
+		{args: []string{"search", "sqlite"}, want: []string{"SQLite tips (2024-08-10 10:00:00) from Tech Blog", "id: {SQLite}", "url: https://tech.example.com/sqlite"},
+			notWant: []string{"Go 1.23 released"}},
+		{args: []string{"search", "go"}, want: []string{"Go 1.23 released", "Rust or Go"}, notWant: []string{"SQLite tips"}},
+		{args: []string{"search", "go", "-rust"}, want: []string{"Go 1.23 released"}, notWant: []string{"Rust or Go"}},
+		{args: []string{"search", "query planner"}, want: []string{"SQLite tips"}},
+		{args: []string{"search", "planner", "query"}, want: []string{"SQLite tips"}},
+		{args: []string{"search", "planner query"}, notWant: []string{"id:"}},
+		{args: []string{"search", "storms", "OR", "election"}, want: []string{"Election results", "Weather warning"}},
+		{args: []string{"search", "kubernetes"}, notWant: []string{"id:"}},
+		{args: []string{"unfollow", "{tech}"}},
+		{args: []string{"search", "sqlite"}, notWant: []string{"id:"}},
+	})
+}
+
+func TestImportExportHandlers(t *testing.T) {
+	server := newFeedServer(t)
+	env := newTestEnv(t)
+	dir := t.TempDir()
+	opmlFile := filepath.Join(dir, "feeds.opml")
+	err := os.WriteFile(opmlFile, []byte(`<?xml version="1.0"?>
+<opml version="1.0"><head><title>Subscriptions</title></head><body>
+<outline text="Tech" title="Tech">
+  <outline text="Tech Blog" type="rss" xmlurl="`+server.URL+`/tech.xml" htmlUrl="https://tech.example.com/"/>
+</outline>
+<outline text="Daily News" type="rss" xmlUrl="`+server.URL+`/news.xml"/>
+<outline text="No url" type="rss"/>
+<outline text="Mail" type="rss" xmlUrl="mailto:news@example.com"/>
+</body></opml>`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.vars["file"] = opmlFile
+	runSteps(t, env, []step{
+		{args: []string{"register", "kahya"}},
+		{args: []string{"import", "opml", "{file}"}, want: []string{
+			"+ Tech Blog (" + server.URL + "/tech.xml)",
+			"+ Daily News (" + server.URL + "/news.xml)",
+			"! No url: missing xmlUrl",
+			"! Mail: invalid url 'mailto:news@example.com', must be http or https",
+		}},
+		{args: []string{"following"}, want: []string{"* kahya follows Tech Blog", "* kahya follows Daily News"}},
+		{args: []string{"import", "opml", "{file}"}, want: []string{"= Tech Blog", "= Daily News"}, notWant: []string{"+ "}},
+		{args: []string{"export", "opml"}, want: []string{
+			`<?xml version="1.0" encoding="UTF-8"?>`,
+			`<opml version="2.0">`,
+			"<title>Feeds followed by kahya</title>",
+			`<outline text="Tech" title="Tech">`,
+			`<outline text="Tech Blog" title="Tech Blog" type="rss" xmlUrl="` + server.URL + `/tech.xml"></outline>`,
+			`</outline>`,
+			`<outline text="Daily News" title="Daily News" type="rss" xmlUrl="` + server.URL + `/news.xml"></outline>`,
+		}},
+		{args: []string{"register", "holly"}},
+		{args: []string{"export", "opml"}, want: []string{"<title>Feeds followed by holly</title>"}, notWant: []string{"xmlUrl"}},
+		{args: []string{"export", "opml", "--all"}, want: []string{"<title>All gator feeds</title>", "Tech Blog", "Daily News"}, notWant: []string{`text="Tech"`}},
+		{args: []string{"import", "csv", "{file}"}, wantErr: "unsupported import format 'csv'"},
+		{args: []string{"export", "csv"}, wantErr: "unsupported export format 'csv'"},
+		{args: []string{"import", "opml", filepath.Join(dir, "missing.opml")}, wantErr: "failed to open file"},
+	})
+}
+
+func TestEnableFeedHandler(t *testing.T) {
+	env := newSeededEnv(t)
+	feed, err := env.s.Db.GetFeed(context.Background(), env.vars["news"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.s.Db.MarkFeedFailed(context.Background(), database.MarkFeedFailedParams{
+		ID:          feed.ID,
+		LastError:   sql.NullString{String: "unexpected response status: 410 Gone", Valid: true},
+		LastStatus:  sql.NullInt32{Int32: http.StatusGone, Valid: true},
+		NextFetchAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		DisabledAt:  sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runSteps(t, env, []step{
+		{args: []string{"feeds"}, want: []string{"* Daily News({news}) from kahya [disabled: unexpected response status: 410 Gone]"}},
+		{args: []string{"enablefeed", "{news}"}},
+		{args: []string{"feeds"}, want: []string{"* Daily News({news}) from kahya\n"}, notWant: []string{"disabled"}},
+		{args: []string{"enablefeed", "{tech}"}},
+		{args: []string{"enablefeed", "https://missing.example.com/feed"}, wantErr: "feed 'https://missing.example.com/feed' not found"},
+		{args: []string{"enablefeed"}, wantErr: "incorrect command usage"},
+	})
+}
+
+func TestDeleteFeedHandler(t *testing.T) {
+	env := newSeededEnv(t)
+	runSteps(t, env, []step{
+		{args: []string{"delfeed", "{news}"}},
+		{args: []string{"feeds"}, want: []string{"Tech Blog"}, notWant: []string{"Daily News"}},
+		{args: []string{"following"}, want: []string{"Tech Blog"}, notWant: []string{"Daily News"}},
+		{args: []string{"browse", "--all", "--limit", "10"}, want: []string{"Go 1.23 released", "SQLite tips", "Rust or Go"},
+			notWant: []string{"Election results", "Weather warning"}},
+		{args: []string{"read", "{Election}"}, wantErr: "not found in followed feeds"},
+		{args: []string{"delfeed", "{news}"}, wantErr: "feed '{news}' not found"},
+		{args: []string{"delfeed"}, wantErr: "incorrect command usage"},
+		{args: []string{"delfeed", "{tech}", "{news}"}, wantErr: "incorrect command usage"},
+	})
+}
+
+func TestMigrateHandler(t *testing.T) {
+	env := newTestEnv(t)
+	dbUrl := "sqlite://" + filepath.Join(t.TempDir(), "gator.db")
+	db, _ := openTestDB(t, dbUrl)
+	env.s.Config.DBUrl, env.s.Conn = dbUrl, db
+	_, latest, err := migrate.Versions(context.Background(), db, config.SQLITE_DRIVER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.vars["latest"] = fmt.Sprintf("%03d", latest)
+	env.vars["previous"] = fmt.Sprintf("%03d", latest-1)
+	runSteps(t, env, []step{
+		{args: []string{"migrate", "version"}, want: []string{fmt.Sprintf("version 0 (latest %d)", latest)}},
+		{args: []string{"migrate", "status"}, want: []string{"* 001_users.sql pending", "* {latest}_"}},
+		{args: []string{"migrate", "up"}, want: []string{"* up 001_users.sql", "* up {latest}_"}},
+		{args: []string{"migrate", "up"}, notWant: []string{"*"}},
+		{args: []string{"migrate", "version"}, want: []string{fmt.Sprintf("version %d (latest %d)", latest, latest)}},
+		{args: []string{"migrate", "status"}, want: []string{"* 001_users.sql applied at ", "* {latest}_"}, notWant: []string{"pending"}},
+		{args: []string{"migrate", "down"}, want: []string{"* down {latest}_"}},
+		{args: []string{"migrate", "version"}, want: []string{fmt.Sprintf("version %d (latest %d)", latest-1, latest)}},
+		{args: []string{"migrate", "status"}, want: []string{"* {previous}_", "applied at ", "* {latest}_", "pending"}},
+		{args: []string{"migrate", "up"}, want: []string{"* up {latest}_"}, notWant: []string{"001_users.sql"}},
+		{args: []string{"migrate", "sideways"}, wantErr: "unknown migrate action 'sideways'"},
+		{args: []string{"migrate"}, wantErr: "incorrect command usage"},
+	})
+	env.s.Config.DBUrl = "sqlite://"
+	runSteps(t, env, []step{
+		{args: []string{"migrate", "version"}, wantErr: "missing sqlite database path"},
+	})
+}
+
+func TestValidateHandler(t *testing.T) {
+	server := newFeedServer(t)
+	env := newTestEnv(t)
+	env.vars["server"] = server.URL
+	runSteps(t, env, []step{
+		{args: []string{"validate", "{server}/tech.xml"}, want: []string{
+			"URL:           {server}/tech.xml",
+			"Status:        200 OK",
+			"Content type:  application/xml",
+			"Encoding:      utf-8",
+			"Format:        RSS 2.0",
+			"Items:         3",
+			"warning: item 1 'Go 1.23 released' has no guid, its link is used instead",
+		}, notWant: []string{"error:"}},
+		{args: []string{"validate", "{server}/news.xml"}, want: []string{"Format:        Atom", "Items:         2"}, notWant: []string{"warning:", "error:"}},
+		{args: []string{"validate", "{server}/invalid.xml"}, want: []string{
+			"Items:         4",
+			"error: item 1 'Undated' has an unparseable date",
+			"error: item 2 'Anonymous' has neither a guid nor a link",
+			"warning: item 3 'First' has no link",
+			"error: item 4 'Second' has the same guid as item 3: 2",
+		}, wantErr: "has 3 errors"},
+		{args: []string{"validate", "{server}/site"}, want: []string{"Content type:  text/html"}, wantErr: "has 1 errors"},
+		{args: []string{"validate", "{server}/missing.xml"}, want: []string{"Status:        404 Not Found", "error: unexpected response status: 404 Not Found"},
+			notWant: []string{"Format:"}, wantErr: "has 1 errors"},
+		{args: []string{"validate"}, wantErr: "incorrect command usage"},
+	})
+}
+
+func TestAggregateFeedHandler(t *testing.T) {
+	server := newFeedServer(t)
+	env := newTestEnv(t)
+	env.vars["server"] = server.URL
+	env.mustRun("register", "kahya")
+	env.mustRun("addfeed", server.URL+"/tech.xml")
+	env.mustRun("addfeed", server.URL+"/news.xml")
+	// agg runs until its context is done; feeds are claimed in no fixed order, so only check the order within one
+	var cancel context.CancelFunc
+	env.ctx, cancel = context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	runSteps(t, env, []step{
+		{args: []string{"agg", "1h", "--workers", "1"}, want: []string{"\t0. Go 1.23 released", "\t1. SQLite tips", "\t2. Rust or Go"}},
+	})
+	// nothing is due again yet
+	env.ctx, cancel = context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	runSteps(t, env, []step{
+		{args: []string{"agg", "1h"}, notWant: []string{"\t0. "}},
+	})
+	env.ctx = context.Background()
+	runSteps(t, env, []step{
+		{args: []string{"browse", "--limit", "10"}, want: []string{"Go 1.23 released", "Election results", "SQLite tips", "Weather warning", "Rust or Go"}},
+		{args: []string{"agg", "soon"}, wantErr: "failed to parse duration"},
+		{args: []string{"agg", "1h", "--workers", "0"}, wantErr: "--workers and --max-per-host must be positive"},
+		{args: []string{"agg", "1h", "--max-per-host", "-1"}, wantErr: "--workers and --max-per-host must be positive"},
+		{args: []string{"agg", "1h", "--workers", "many"}, wantErr: "incorrect command usage"},
+		{args: []string{"agg"}, wantErr: "incorrect command usage"},
+	})
+}
+
+func TestHelpHandler(t *testing.T) {
+	env := newTestEnv(t)
+	runSteps(t, env, []step{
+		{args: []string{"help"}, want: []string{"Gator is an RSS feed aggregator", "usage: gator <command> [arguments]", "commands:",
+			"  addfeed", "  browse", "  users", "Run 'gator help <command>'"}, notWant: []string{"__complete"}},
+		{args: []string{"help", "browse"}, want: []string{"usage: gator browse [limit] [flags]", "Browse unread posts", "flags:",
+			"--limit n", "number of posts to show (default 2)", "--all"}},
+		{args: []string{"help", "login"}, want: []string{"usage: gator login <userName>"}, notWant: []string{"flags:"}},
+		{args: []string{"browse", "--help"}, want: []string{"usage: gator browse"}},
+		{args: []string{"help", "nope"}, wantErr: "command 'nope' not registered"},
+		{args: []string{"help", "browse", "login"}, wantErr: "incorrect command usage"},
+		{args: []string{"nope"}, wantErr: "command 'nope' not registered, see 'gator help'"},
+	})
+}
+
+func TestCompletionHandlers(t *testing.T) {
+	env := newSeededEnv(t)
+	env.mustRun("register", "holly")
+	env.mustRun("login", "kahya")
+	runSteps(t, env, []step{
+		{args: []string{"completion", "bash"}, want: []string{"_gator()", "gator __complete", "complete -F _gator gator"}},
+		{args: []string{"completion", "zsh"}, want: []string{"#compdef gator", "compdef _gator gator"}},
+		{args: []string{"completion", "fish"}, want: []string{"complete -c gator"}},
+		{args: []string{"completion", "tcsh"}, wantErr: "unsupported shell 'tcsh'"},
+		{args: []string{"completion"}, wantErr: "incorrect command usage"},
+		{args: []string{"__complete"}, want: []string{"addfeed\n", "browse\n", "users\n"}, notWant: []string{"__complete"}},
+		{args: []string{"__complete", "b"}, want: []string{"bookmark\n", "bookmarks\n", "browse\n"}, notWant: []string{"addfeed"}},
+		{args: []string{"__complete", "login", ""}, want: []string{"holly\n"}},
+		{args: []string{"__complete", "login", "k"}, want: []string{"kahya\n"}, notWant: []string{"holly"}},
+		{args: []string{"__complete", "delfeed", ""}, want: []string{"{news}\n"}},
+		{args: []string{"__complete", "delfeed", ""}, want: []string{"{tech}\n"}},
+		{args: []string{"__complete", "browse", "-"}, want: []string{"--limit\n", "--page\n", "--all\n"}},
+		{args: []string{"__complete", "browse", "--sort", ""}, want: []string{"asc\n", "desc\n"}},
+		{args: []string{"__complete", "browse", "--all", "--sort", "d"}, want: []string{"desc\n"}, notWant: []string{"asc"}},
+		{args: []string{"__complete", "help", "comp"}, want: []string{"completion\n"}},
+		{args: []string{"__complete", "import", "opml", ""}, notWant: []string{"\n"}},
+		{args: []string{"__complete", "nope", ""}, notWant: []string{"\n"}},
+		{args: []string{"__complete", "follow", ""}, notWant: []string{"\n"}},
+		{args: []string{"unfollow", "{news}"}},
+		{args: []string{"__complete", "unfollow", ""}, want: []string{"{tech}\n"}, notWant: []string{"{news}"}},
+		{args: []string{"__complete", "browse", "--feed", ""}, want: []string{"{tech}\n"}, notWant: []string{"{news}"}},
+		{args: []string{"__complete", "mark-all-read", ""}, want: []string{"{tech}\n"}, notWant: []string{"{news}"}},
+		{args: []string{"__complete", "follow", ""}, want: []string{"{news}\n"}, notWant: []string{"{tech}"}},
+		{args: []string{"__complete", "delfeed", ""}, want: []string{"{news}\n"}},
+		{args: []string{"login", "holly"}},
+		{args: []string{"__complete", "unfollow", ""}, notWant: []string{"\n"}},
+		{args: []string{"__complete", "follow", ""}, want: []string{"{news}\n"}},
+		{args: []string{"__complete", "follow", ""}, want: []string{"{tech}\n"}},
+	})
+}
@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const bashCompletion = `# bash completion for gator, generated by 'gator completion bash'
+_gator() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -r -a words <<< "$line"
+    [[ "$line" =~ [[:space:]]$ ]] && words+=("")
+    local cur="${words[${#words[@]}-1]}"
+    local IFS=$'\n'
+    COMPREPLY=($(gator __complete "${words[@]:1}" 2>/dev/null))
+    # bash splits words on ':', so only complete what follows the last one, as in URLs
+    if [[ "$cur" == *:* && "$COMP_WORDBREAKS" == *:* ]]; then
+        local prefix="${cur%"${cur##*:}"}"
+        COMPREPLY=("${COMPREPLY[@]#"$prefix"}")
+    fi
+}
+complete -F _gator gator
+`
+
+const zshCompletion = `#compdef gator
+# zsh completion for gator, generated by 'gator completion zsh'
+_gator() {
+    local -a candidates
+    candidates=(${(f)"$(gator __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    compadd -- "${candidates[@]}"
+}
+if [[ "$funcstack[1]" == "_gator" ]]; then
+    _gator "$@"
+else
+    compdef _gator gator
+fi
+`
+
+const fishCompletion = `# fish completion for gator, generated by 'gator completion fish'
+function __gator_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    gator __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c gator -f -a '(__gator_complete)'
+`
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+func CompletionHandler(ctx context.Context, s *State, cmd Command) error {
+	script, ok := completionScripts[cmd.Arguments[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell '%s', must be bash, zsh or fish", cmd.Arguments[0])
+	}
+	fmt.Fprint(s.Out, script)
+	return nil
+}
+
+// CompleteHandler prints the completions of the last of the given words, which follow "gator" on the command line.
+// Errors are ignored so that completion never gets in the way.
+func (c *Commands) CompleteHandler(ctx context.Context, s *State, cmd Command) error {
+	words := cmd.Arguments
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	candidates, _ := c.complete(ctx, s, words[:len(words)-1], current)
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			fmt.Fprintln(s.Out, candidate)
+		}
+	}
+	return nil
+}
+
+func (c *Commands) complete(ctx context.Context, s *State, words []string, current string) ([]string, error) {
+	if len(words) == 0 {
+		return c.visibleNames(), nil
+	}
+	spec, ok := c.CommandRegistry[words[0]]
+	if !ok {
+		return nil, nil
+	}
+	// walk the words like parse does, to find whether current is a flag, a flag's value or which positional argument
+	var pending *Flag
+	positional := 0
+	flagsDone := len(spec.Flags) == 0
+	for _, word := range words[1:] {
+		switch {
+		case pending != nil:
+			pending = nil
+		case !flagsDone && word == "--":
+			flagsDone = true
+		case !flagsDone && strings.HasPrefix(word, "-"):
+			name, _, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			if f, ok := spec.flagNamed(name); ok && !hasValue && !f.isBool() {
+				pending = &f
+			}
+		default:
+			positional++
+		}
+	}
+	if pending != nil {
+		return completeWith(ctx, s, pending.Complete)
+	}
+	if !flagsDone && strings.HasPrefix(current, "-") {
+		names := make([]string, 0, len(spec.Flags))
+		for _, f := range spec.Flags {
+			names = append(names, "--"+f.Name)
+		}
+		return names, nil
+	}
+	switch {
+	case positional < len(spec.Args):
+		return completeWith(ctx, s, spec.Args[positional].Complete)
+	case len(spec.Args) > 0 && spec.Args[len(spec.Args)-1].Variadic:
+		return completeWith(ctx, s, spec.Args[len(spec.Args)-1].Complete)
+	}
+	return nil, nil
+}
+
+func completeWith(ctx context.Context, s *State, complete Completer) ([]string, error) {
+	if complete == nil {
+		return nil, nil
+	}
+	return complete(ctx, s)
+}
+
+func (c *Commands) visibleNames() []string {
+	var names []string
+	for _, name := range slices.Sorted(maps.Keys(c.CommandRegistry)) {
+		if !c.CommandRegistry[name].Hidden {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (spec Spec) flagNamed(name string) (Flag, bool) {
+	for _, f := range spec.Flags {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+func (f Flag) isBool() bool {
+	_, ok := f.Default.(bool)
+	return ok
+}
+
+// values completes a fixed set of values.
+func values(values ...string) Completer {
+	return func(ctx context.Context, s *State) ([]string, error) {
+		return values, nil
+	}
+}
+
+func completeUserNames(ctx context.Context, s *State) ([]string, error) {
+	users, err := s.Db.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names, nil
+}
+
+func completeFeedUrls(ctx context.Context, s *State) ([]string, error) {
+	feeds, err := s.Db.GetUserFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, len(feeds))
+	for _, feed := range feeds {
+		urls = append(urls, feed.Url)
+	}
+	return urls, nil
+}
+
+func completeFollowedFeedUrls(ctx context.Context, s *State) ([]string, error) {
+	user, err := s.Db.GetUser(ctx, s.Config.UserName)
+	if err != nil {
+		return nil, err
+	}
+	follows, err := s.Db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		urls = append(urls, follow.FeedUrl)
+	}
+	return urls, nil
+}
+
+func completeUnfollowedFeedUrls(ctx context.Context, s *State) ([]string, error) {
+	urls, err := completeFeedUrls(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	followed, err := completeFollowedFeedUrls(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(urls, func(url string) bool { return slices.Contains(followed, url) }), nil
+}
+
+func (c *Commands) completeCommandNames(ctx context.Context, s *State) ([]string, error) {
+	return c.visibleNames(), nil
+}
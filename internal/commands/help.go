@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func (c *Commands) HelpHandler(ctx context.Context, s *State, cmd Command) error {
+	if len(cmd.Arguments) == 1 {
+		spec, ok := c.CommandRegistry[cmd.Arguments[0]]
+		if !ok {
+			return fmt.Errorf("command '%s' not registered", cmd.Arguments[0])
+		}
+		spec.printHelp(s.Out)
+		return nil
+	}
+	fmt.Fprintln(s.Out, "Gator is an RSS feed aggregator for the terminal.")
+	fmt.Fprintln(s.Out)
+	fmt.Fprintln(s.Out, "usage: gator <command> [arguments]")
+	fmt.Fprintln(s.Out)
+	fmt.Fprintln(s.Out, "commands:")
+	w := tabwriter.NewWriter(s.Out, 0, 4, 2, ' ', 0)
+	for _, name := range c.visibleNames() {
+		fmt.Fprintf(w, "  %s\t%s\n", name, c.CommandRegistry[name].Summary)
+	}
+	w.Flush()
+	fmt.Fprintln(s.Out)
+	fmt.Fprintln(s.Out, "Run 'gator help <command>' for more about a command.")
+	return nil
+}
+
+func (spec Spec) printHelp(out io.Writer) {
+	fmt.Fprintf(out, "usage: gator %s\n\n%s\n", spec.Usage(), spec.Summary)
+	if len(spec.Flags) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "\nflags:")
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, f := range spec.Flags {
+		name := "--" + f.Name
+		if f.Value != "" {
+			name += " " + f.Value
+		}
+		if def := f.defaultText(); def != "" {
+			fmt.Fprintf(w, "  %s\t%s (default %s)\n", name, f.Usage, def)
+		} else {
+			fmt.Fprintf(w, "  %s\t%s\n", name, f.Usage)
+		}
+	}
+	w.Flush()
+}
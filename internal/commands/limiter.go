@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// hostLimiter caps the number of concurrent requests sent to the same host.
+type hostLimiter struct {
+	mu    sync.Mutex
+	limit int
+	hosts map[string]chan struct{}
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{
+		limit: limit,
+		hosts: make(map[string]chan struct{}),
+	}
+}
+
+// acquire blocks until a slot for the feed's host is free, or ctx is done, and returns the function that releases it.
+func (l *hostLimiter) acquire(ctx context.Context, feedUrl string) (func(), error) {
+	host := feedUrl
+	if u, err := url.Parse(feedUrl); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	l.mu.Lock()
+	slots, ok := l.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, l.limit)
+		l.hosts[host] = slots
+	}
+	l.mu.Unlock()
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
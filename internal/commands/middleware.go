@@ -6,12 +6,12 @@ import (
 	"github.com/charlesaraya/gator/internal/database"
 )
 
-func LoggedInMiddleware(handler func(s *State, cmd Command, user database.User) error) func(*State, Command) error {
-	return func(s *State, cmd Command) error {
-		user, err := s.Db.GetUser(context.Background(), s.Config.UserName)
+func LoggedInMiddleware(handler func(ctx context.Context, s *State, cmd Command, user database.User) error) func(context.Context, *State, Command) error {
+	return func(ctx context.Context, s *State, cmd Command) error {
+		user, err := s.Db.GetUser(ctx, s.Config.UserName)
 		if err != nil {
 			return err
 		}
-		return handler(s, cmd, user)
+		return handler(ctx, s, cmd, user)
 	}
 }
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/charlesaraya/gator/internal/database"
+	"github.com/charlesaraya/gator/internal/opml"
+	"github.com/google/uuid"
+)
+
+func ImportHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	format, fileName := cmd.Arguments[0], cmd.Arguments[1]
+	if format != "opml" {
+		return fmt.Errorf("unsupported import format '%s', must be opml", format)
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	doc, err := opml.Parse(file)
+	if err != nil {
+		return err
+	}
+	follows, err := s.Db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get followed feeds: %w", err)
+	}
+	followed := make(map[uuid.UUID]bool, len(follows))
+	for _, follow := range follows {
+		followed[follow.FeedID] = true
+	}
+	var added, created, existing, invalid int
+	for _, entry := range doc.Feeds() {
+		if err := validateFeedUrl(entry.URL); err != nil {
+			fmt.Fprintf(s.Out, "! %s: %v\n", entry.Name, err)
+			invalid++
+			continue
+		}
+		name := entry.Name
+		if name == "" {
+			name = entry.URL
+		}
+		feed, err := s.Db.GetFeed(ctx, entry.URL)
+		if errors.Is(err, sql.ErrNoRows) {
+			feed, err = s.Db.CreateFeed(ctx, database.CreateFeedParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				Name:      name,
+				Url:       entry.URL,
+				UserID:    user.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create feed '%s': %w", entry.URL, err)
+			}
+			created++
+		} else if err != nil {
+			return fmt.Errorf("failed to get feed '%s': %w", entry.URL, err)
+		}
+		if followed[feed.ID] {
+			fmt.Fprintf(s.Out, "= %s (%s)\n", feed.Name, feed.Url)
+			existing++
+			continue
+		}
+		_, err = s.Db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+			Folder:    nullString(entry.Category),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to follow feed '%s': %w", entry.URL, err)
+		}
+		followed[feed.ID] = true
+		fmt.Fprintf(s.Out, "+ %s (%s)\n", feed.Name, feed.Url)
+		added++
+	}
+	log.Printf("Import: '%s' followed %d feeds (%d new), %d already followed, %d invalid", user.Name, added, created, existing, invalid)
+	return nil
+}
+
+func ExportHandler(ctx context.Context, s *State, cmd Command, user database.User) error {
+	if format := cmd.Arguments[0]; format != "opml" {
+		return fmt.Errorf("unsupported export format '%s', must be opml", format)
+	}
+	follows, err := s.Db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get followed feeds: %w", err)
+	}
+	var feeds []opml.Feed
+	title := fmt.Sprintf("Feeds followed by %s", user.Name)
+	if cmd.BoolFlag("all") {
+		folders := make(map[uuid.UUID]string, len(follows))
+		for _, follow := range follows {
+			folders[follow.FeedID] = follow.Folder.String
+		}
+		allFeeds, err := s.Db.GetUserFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get all feeds: %w", err)
+		}
+		for _, feed := range allFeeds {
+			feeds = append(feeds, opml.Feed{Name: feed.Name, URL: feed.Url, SiteURL: feed.SiteUrl.String, Category: folders[feed.ID]})
+		}
+		title = "All gator feeds"
+	} else {
+		for _, follow := range follows {
+			feeds = append(feeds, opml.Feed{Name: follow.FeedName, URL: follow.FeedUrl, SiteURL: follow.FeedSiteUrl.String, Category: follow.Folder.String})
+		}
+	}
+	if err := opml.New(title, feeds).Write(s.Out); err != nil {
+		return err
+	}
+	log.Printf("Export: %v feeds", len(feeds))
+	return nil
+}
+
+func validateFeedUrl(feedUrl string) error {
+	if feedUrl == "" {
+		return errors.New("missing xmlUrl")
+	}
+	u, err := url.Parse(feedUrl)
+	if err != nil {
+		return fmt.Errorf("invalid url '%s'", feedUrl)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url '%s', must be http or https", feedUrl)
+	}
+	return nil
+}
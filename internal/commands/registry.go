@@ -0,0 +1,412 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Command struct {
+	Name      string
+	Arguments []string
+	Flags     *flag.FlagSet
+}
+
+// Spec declares a command: how it's invoked, how it's documented in help and what runs it.
+type Spec struct {
+	Name    string
+	Summary string
+	Args    []Arg
+	Flags   []Flag
+	Handler func(context.Context, *State, Command) error
+	// SkipSchemaCheck lets the command run before the database schema is up to date.
+	SkipSchemaCheck bool
+	// Hidden commands are left out of help and completion.
+	Hidden bool
+}
+
+// Completer returns the values an argument or flag can take, for shell completion.
+type Completer func(ctx context.Context, s *State) ([]string, error)
+
+// Arg is a positional argument of a command.
+type Arg struct {
+	Name     string
+	Optional bool
+	// Variadic takes any remaining arguments, so it must come last.
+	Variadic bool
+	Complete Completer
+}
+
+// Flag is a command flag. Its type is that of its Default: bool, int or string.
+type Flag struct {
+	Name     string
+	Value    string
+	Default  any
+	Usage    string
+	Complete Completer
+}
+
+type Commands struct {
+	CommandRegistry map[string]Spec
+}
+
+func (c *Commands) Run(ctx context.Context, s *State, cmd Command) error {
+	spec, ok := c.CommandRegistry[cmd.Name]
+	if !ok {
+		return fmt.Errorf("command '%s' not registered, see 'gator help'", cmd.Name)
+	}
+	parsed, err := spec.parse(cmd.Arguments)
+	if errors.Is(err, flag.ErrHelp) {
+		spec.printHelp(s.Out)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("incorrect command usage: %w.\nusage: gator %s", err, spec.Usage())
+	}
+	if err := spec.Handler(ctx, s, parsed); err != nil {
+		return fmt.Errorf("failed to run command '%s': %w", cmd.Name, err)
+	}
+	return nil
+}
+
+func (c *Commands) Register(spec Spec) error {
+	if _, ok := c.CommandRegistry[spec.Name]; ok {
+		return fmt.Errorf("command '%s' already registered", spec.Name)
+	}
+	c.CommandRegistry[spec.Name] = spec
+	return nil
+}
+
+func (c *Commands) Lookup(name string) (Spec, bool) {
+	spec, ok := c.CommandRegistry[name]
+	return spec, ok
+}
+
+// Usage returns the command's usage line, such as "browse [limit] [flags]".
+func (spec Spec) Usage() string {
+	var usage strings.Builder
+	usage.WriteString(spec.Name)
+	for _, arg := range spec.Args {
+		if arg.Optional {
+			fmt.Fprintf(&usage, " [%s]", arg.Name)
+		} else {
+			fmt.Fprintf(&usage, " <%s>", arg.Name)
+		}
+		if arg.Variadic {
+			usage.WriteString("...")
+		}
+	}
+	if len(spec.Flags) > 0 {
+		usage.WriteString(" [flags]")
+	}
+	return usage.String()
+}
+
+// parse splits args into flags and positional arguments. Flags may appear anywhere before a "--".
+func (spec Spec) parse(args []string) (Command, error) {
+	flags := flag.NewFlagSet(spec.Name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	for _, f := range spec.Flags {
+		f.define(flags)
+	}
+	var positional []string
+	if len(spec.Flags) == 0 {
+		// commands without flags get their arguments as they are, so that search can take -term
+		if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+			return Command{}, flag.ErrHelp
+		}
+		positional = args
+	} else {
+		for {
+			if err := flags.Parse(args); err != nil {
+				return Command{}, err
+			}
+			rest := flags.Args()
+			if len(rest) == 0 {
+				break
+			}
+			if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+				positional = append(positional, rest...)
+				break
+			}
+			positional = append(positional, rest[0])
+			args = rest[1:]
+		}
+	}
+	if err := spec.checkArgs(positional); err != nil {
+		return Command{}, err
+	}
+	return Command{
+		Name:      spec.Name,
+		Arguments: positional,
+		Flags:     flags,
+	}, nil
+}
+
+// checkArgs checks there are enough arguments for the required ones. An optional argument may come
+// before a required one, in which case it's only given when there are more arguments than required ones.
+func (spec Spec) checkArgs(args []string) error {
+	given := len(args)
+	for _, arg := range spec.Args {
+		if arg.Optional {
+			continue
+		}
+		if given == 0 {
+			return fmt.Errorf("missing <%s>", arg.Name)
+		}
+		given--
+	}
+	variadic := len(spec.Args) > 0 && spec.Args[len(spec.Args)-1].Variadic
+	if len(args) > len(spec.Args) && !variadic {
+		return fmt.Errorf("unexpected argument '%s'", args[len(spec.Args)])
+	}
+	return nil
+}
+
+func (f Flag) define(flags *flag.FlagSet) {
+	switch value := f.Default.(type) {
+	case bool:
+		flags.Bool(f.Name, value, f.Usage)
+	case int:
+		flags.Int(f.Name, value, f.Usage)
+	case string:
+		flags.String(f.Name, value, f.Usage)
+	default:
+		panic(fmt.Sprintf("flag '--%s' has unsupported type %T", f.Name, f.Default))
+	}
+}
+
+// defaultText returns how the flag's default is shown in help, or "" if it's the zero value.
+func (f Flag) defaultText() string {
+	switch value := f.Default.(type) {
+	case bool:
+		if value {
+			return "true"
+		}
+	case int:
+		if value != 0 {
+			return strconv.Itoa(value)
+		}
+	case string:
+		return value
+	}
+	return ""
+}
+
+func (cmd Command) flag(name string) any {
+	f := cmd.Flags.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("flag '--%s' not declared by command '%s'", name, cmd.Name))
+	}
+	return f.Value.(flag.Getter).Get()
+}
+
+func (cmd Command) BoolFlag(name string) bool {
+	return cmd.flag(name).(bool)
+}
+
+func (cmd Command) IntFlag(name string) int {
+	return cmd.flag(name).(int)
+}
+
+func (cmd Command) StringFlag(name string) string {
+	return cmd.flag(name).(string)
+}
+
+// GetCommands returns the registry of every gator command.
+func GetCommands() Commands {
+	cmds := Commands{
+		CommandRegistry: make(map[string]Spec),
+	}
+	for _, spec := range []Spec{
+		{
+			Name:    "login",
+			Summary: "Log in as an existing user.",
+			Args:    []Arg{{Name: "userName", Complete: completeUserNames}},
+			Handler: LoginHandler,
+		},
+		{
+			Name:    "register",
+			Summary: "Register a new user and log in as them.",
+			Args:    []Arg{{Name: "userName"}},
+			Handler: RegisterHandler,
+		},
+		{
+			Name:    "users",
+			Summary: "List all users, marking the current one.",
+			Handler: UsersHandler,
+		},
+		{
+			Name:    "reset",
+			Summary: "Delete every user, along with their feeds and posts.",
+			Handler: ResetHandler,
+		},
+		{
+			Name:            "migrate",
+			Summary:         "Apply, roll back or inspect the embedded schema migrations.",
+			Args:            []Arg{{Name: "up|down|status|version", Complete: values("up", "down", "status", "version")}},
+			Handler:         MigrateHandler,
+			SkipSchemaCheck: true,
+		},
+		{
+			Name:    "agg",
+			Summary: "Keep fetching due feeds with a pool of workers, at most a few at once per host.",
+			Args:    []Arg{{Name: "timeBetweenRequests"}},
+			Flags: []Flag{
+				{Name: "workers", Value: "n", Default: defaultAggWorkers, Usage: "number of feeds to fetch at once"},
+				{Name: "max-per-host", Value: "n", Default: defaultAggMaxPerHost, Usage: "number of feeds to fetch at once from the same host"},
+			},
+			Handler: AggregateFeedHandler,
+		},
+		{
+			Name:    "addfeed",
+			Summary: "Add a feed, named after its title by default, and follow it. Given a website instead, add the feed it advertises, asking which if there are several.",
+			Args:    []Arg{{Name: "feedName", Optional: true}, {Name: "feedUrl"}},
+			Handler: LoggedInMiddleware(AddFeedHandler),
+		},
+		{
+			Name:    "delfeed",
+			Summary: "Delete a feed and its posts.",
+			Args:    []Arg{{Name: "feedUrl", Complete: completeFeedUrls}},
+			Handler: DeleteFeedHandler,
+		},
+		{
+			Name:    "enablefeed",
+			Summary: "Re-enable a feed that was disabled after too many failed fetches.",
+			Args:    []Arg{{Name: "feedUrl", Complete: completeFeedUrls}},
+			Handler: EnableFeedHandler,
+		},
+		{
+			Name:    "feeds",
+			Summary: "List all feeds, flagging failing and disabled ones.",
+			Handler: FeedsHandler,
+		},
+		{
+			Name:    "follow",
+			Summary: "Follow an existing feed.",
+			Args:    []Arg{{Name: "feedUrl", Complete: completeUnfollowedFeedUrls}},
+			Handler: LoggedInMiddleware(FollowFeedsHandler),
+		},
+		{
+			Name:    "following",
+			Summary: "List the feeds you follow.",
+			Handler: LoggedInMiddleware(FollowedFeedsHandler),
+		},
+		{
+			Name:    "unfollow",
+			Summary: "Unfollow a feed.",
+			Args:    []Arg{{Name: "feedUrl", Complete: completeFollowedFeedUrls}},
+			Handler: LoggedInMiddleware(UnFollowFeedHandler),
+		},
+		{
+			Name:    "browse",
+			Summary: "Browse unread posts across followed feeds, newest first.",
+			Args:    []Arg{{Name: "limit", Optional: true}},
+			Flags: []Flag{
+				{Name: "limit", Value: "n", Default: int(defaultBrowseLimit), Usage: "number of posts to show"},
+				{Name: "page", Value: "n", Default: 1, Usage: "page of posts to show; deep pages are slower than --after"},
+				{Name: "after", Value: "postId", Default: "", Usage: "show the posts that come after this post"},
+				{Name: "feed", Value: "feedUrl", Default: "", Usage: "only show posts from this feed", Complete: completeFollowedFeedUrls},
+				{Name: "since", Value: "time", Default: "", Usage: "only show posts published since this date, or this long ago (e.g. 24h, 7d)"},
+				{Name: "until", Value: "time", Default: "", Usage: "only show posts published before this date, or this long ago"},
+				{Name: "sort", Value: "asc|desc", Default: "desc", Usage: "order posts by publication date", Complete: values("asc", "desc")},
+				{Name: "all", Default: false, Usage: "include read posts"},
+			},
+			Handler: LoggedInMiddleware(BrowsePostsHandler),
+		},
+		{
+			Name:    "search",
+			Summary: `Search posts in followed feeds. Supports "exact phrases", -excluded words and OR.`,
+			Args:    []Arg{{Name: "query", Variadic: true}},
+			Handler: LoggedInMiddleware(SearchPostsHandler),
+		},
+		{
+			Name:    "read",
+			Summary: "Mark a post as read.",
+			Args:    []Arg{{Name: "postId"}},
+			Handler: LoggedInMiddleware(ReadPostHandler),
+		},
+		{
+			Name:    "unread",
+			Summary: "Mark a post as unread.",
+			Args:    []Arg{{Name: "postId"}},
+			Handler: LoggedInMiddleware(UnreadPostHandler),
+		},
+		{
+			Name:    "mark-all-read",
+			Summary: "Mark every post across followed feeds, or only the given feed's, as read.",
+			Args:    []Arg{{Name: "feedUrl", Optional: true, Complete: completeFollowedFeedUrls}},
+			Handler: LoggedInMiddleware(MarkAllReadHandler),
+		},
+		{
+			Name:    "bookmark",
+			Summary: "Save a post to read later.",
+			Args:    []Arg{{Name: "postId"}},
+			Handler: LoggedInMiddleware(BookmarkPostHandler),
+		},
+		{
+			Name:    "unbookmark",
+			Summary: "Remove a saved post.",
+			Args:    []Arg{{Name: "postId"}},
+			Handler: LoggedInMiddleware(UnbookmarkPostHandler),
+		},
+		{
+			Name:    "bookmarks",
+			Summary: "List saved posts, newest first.",
+			Handler: LoggedInMiddleware(BookmarksHandler),
+		},
+		{
+			Name:            "validate",
+			Summary:         "Check a feed for problems, such as unparseable dates or duplicate guids, failing if it has errors.",
+			Args:            []Arg{{Name: "feedUrl", Complete: completeFeedUrls}},
+			Handler:         ValidateHandler,
+			SkipSchemaCheck: true,
+		},
+		{
+			Name:    "import",
+			Summary: "Follow the feeds listed in an OPML file, adding the ones gator doesn't know yet.",
+			Args:    []Arg{{Name: "opml", Complete: values("opml")}, {Name: "file"}},
+			Handler: LoggedInMiddleware(ImportHandler),
+		},
+		{
+			Name:    "export",
+			Summary: "Print the feeds you follow as an OPML document, keeping their folders.",
+			Args:    []Arg{{Name: "opml", Complete: values("opml")}},
+			Flags: []Flag{
+				{Name: "all", Default: false, Usage: "export every feed, not only the ones you follow"},
+			},
+			Handler: LoggedInMiddleware(ExportHandler),
+		},
+		{
+			Name:            "help",
+			Summary:         "Show the available commands, or how to use one of them.",
+			Args:            []Arg{{Name: "command", Optional: true, Complete: cmds.completeCommandNames}},
+			Handler:         cmds.HelpHandler,
+			SkipSchemaCheck: true,
+		},
+		{
+			Name:            "completion",
+			Summary:         "Print a shell completion script, e.g. source <(gator completion bash).",
+			Args:            []Arg{{Name: "bash|zsh|fish", Complete: values("bash", "zsh", "fish")}},
+			Handler:         CompletionHandler,
+			SkipSchemaCheck: true,
+		},
+		{
+			Name:            "__complete",
+			Summary:         "Print the completions of the last word, for the completion scripts.",
+			Args:            []Arg{{Name: "words", Optional: true, Variadic: true}},
+			Handler:         cmds.CompleteHandler,
+			SkipSchemaCheck: true,
+			Hidden:          true,
+		},
+	} {
+		if err := cmds.Register(spec); err != nil {
+			panic(err)
+		}
+	}
+	return cmds
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charlesaraya/gator/internal/rss"
+)
+
+// ValidateHandler fetches a feed and reports what agg would make of it. Items agg would drop and
+// duplicate guids, whose posts would overwrite each other, are errors; the command fails if there are any.
+func ValidateHandler(ctx context.Context, s *State, cmd Command) error {
+	feedUrl := cmd.Arguments[0]
+	fetchedAt := time.Now()
+	feed, info, fetchErr := rss.FetchFeed(ctx, feedUrl, rss.Validators{})
+	w := tabwriter.NewWriter(s.Out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "URL:\t%s\n", feedUrl)
+	if info.StatusCode != 0 {
+		fmt.Fprintf(w, "Status:\t%d %s\n", info.StatusCode, http.StatusText(info.StatusCode))
+		fmt.Fprintf(w, "Content type:\t%s\n", info.ContentType)
+	}
+	if info.Encoding != "" {
+		fmt.Fprintf(w, "Encoding:\t%s\n", info.Encoding)
+	}
+	if feed != nil {
+		fmt.Fprintf(w, "Format:\t%s\n", feed.Format)
+		fmt.Fprintf(w, "Items:\t%d\n", len(feed.Channel.Items))
+	}
+	w.Flush()
+
+	var errs, warnings int
+	report := func(isError bool, format string, args ...any) {
+		level := "warning"
+		if isError {
+			level = "error"
+			errs++
+		} else {
+			warnings++
+		}
+		fmt.Fprintf(s.Out, "%s: %s\n", level, fmt.Sprintf(format, args...))
+	}
+	if info.Encoding != "" && info.Encoding != "utf-8" && info.Encoding != "us-ascii" {
+		report(false, "encoding is %s, but gator only decodes utf-8", info.Encoding)
+	}
+	if fetchErr != nil {
+		report(true, "%v", fetchErr)
+	} else {
+		if len(feed.Channel.Items) == 0 {
+			report(false, "feed has no items")
+		}
+		seen := make(map[string]int)
+		for i, item := range feed.Channel.Items {
+			name := fmt.Sprintf("item %d '%s'", i+1, strings.TrimSpace(item.Title))
+			if _, err := item.PublishedAt(fetchedAt); err != nil {
+				report(true, "%s has an unparseable date: %v", name, err)
+			}
+			switch {
+			case item.ID() == "":
+				report(true, "%s has neither a guid nor a link", name)
+			case strings.TrimSpace(item.GUID) == "":
+				report(false, "%s has no guid, its link is used instead", name)
+			case strings.TrimSpace(item.Link) == "":
+				report(false, "%s has no link", name)
+			}
+			if id := item.ID(); id != "" {
+				if first, ok := seen[id]; ok {
+					report(true, "%s has the same guid as item %d: %s", name, first, id)
+				} else {
+					seen[id] = i + 1
+				}
+			}
+		}
+	}
+	log.Printf("Validate: %s: %d errors, %d warnings", feedUrl, errs, warnings)
+	if errs > 0 {
+		return fmt.Errorf("feed '%s' has %d errors", feedUrl, errs)
+	}
+	return nil
+}
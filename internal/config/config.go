@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
 const (
-	CONFIG_FILE string = ".gatorconfig.json"
+	CONFIG_FILE     string = ".gatorconfig.json"
+	POSTGRES_DRIVER string = "postgres"
+	SQLITE_DRIVER   string = "sqlite"
 )
 
 func getConfigFilePath() (string, error) {
@@ -60,10 +64,42 @@ func Read() (Config, error) {
 	return config, nil
 }
 
+// Driver returns the database driver and data source name for the db_url, chosen by its scheme:
+// sqlite://<path> (or sqlite:<path>) for SQLite, anything else for PostgreSQL.
+func (c *Config) Driver() (string, string, error) {
+	path, ok := strings.CutPrefix(c.DBUrl, "sqlite://")
+	if !ok {
+		path, ok = strings.CutPrefix(c.DBUrl, "sqlite:")
+	}
+	if !ok {
+		return POSTGRES_DRIVER, c.DBUrl, nil
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get home dir: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	if path == "" {
+		return "", "", fmt.Errorf("missing sqlite database path in db_url '%s'", c.DBUrl)
+	}
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"
+	return SQLITE_DRIVER, dsn, nil
+}
+
 func LoadDB(cfg *Config) (*sql.DB, error) {
-	db, err := sql.Open("postgres", cfg.DBUrl)
+	driver, dsn, err := cfg.Driver()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get db driver: %w", err)
+	}
+	db, err := sql.Open(driver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db: %w", err)
 	}
+	if driver == SQLITE_DRIVER {
+		// SQLite allows a single writer, so serialize access rather than failing with busy errors
+		db.SetMaxOpenConns(1)
+	}
 	return db, nil
 }
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,9 +15,9 @@ import (
 
 const createFeedFollow = `-- name: CreateFeedFollow :one
 WITH inserted AS (
-  INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-  VALUES ($1, $2, $3, $4, $5)
-  RETURNING id, created_at, updated_at, user_id, feed_id
+  INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, folder)
+  VALUES ($1, $2, $3, $4, $5, $6)
+  RETURNING id, created_at, updated_at, user_id, feed_id, folder
 )
 SELECT 
   inserted.id, 
@@ -35,6 +36,7 @@ type CreateFeedFollowParams struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Folder    sql.NullString
 }
 
 type CreateFeedFollowRow struct {
@@ -52,6 +54,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		arg.UpdatedAt,
 		arg.UserID,
 		arg.FeedID,
+		arg.Folder,
 	)
 	var i CreateFeedFollowRow
 	err := row.Scan(
@@ -93,17 +96,23 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 }
 
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
-SELECT ff.id, ff.created_at, ff.updated_at, f.name AS feed_name
+SELECT ff.id, ff.created_at, ff.updated_at, ff.feed_id, ff.folder, f.name AS feed_name, f.url AS feed_url,
+  f.title AS feed_title, f.site_url AS feed_site_url
 FROM feed_follows AS ff
 JOIN feeds AS f ON ff.feed_id = f.id
 WHERE ff.user_id = $1
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	FeedName  string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	FeedID      uuid.UUID
+	Folder      sql.NullString
+	FeedName    string
+	FeedUrl     string
+	FeedTitle   sql.NullString
+	FeedSiteUrl sql.NullString
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -119,7 +128,12 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.ID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.FeedID,
+			&i.Folder,
 			&i.FeedName,
+			&i.FeedUrl,
+			&i.FeedTitle,
+			&i.FeedSiteUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -7,26 +7,73 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const claimNextFeedToFetch = `-- name: ClaimNextFeedToFetch :one
+UPDATE feeds
+SET last_fetched_at = NOW(), next_fetch_at = NOW() + INTERVAL '10 minutes'
+WHERE id = (
+    SELECT f.id
+    FROM feeds AS f
+    WHERE f.disabled_at IS NULL AND (f.next_fetch_at IS NULL OR f.next_fetch_at <= NOW())
+    ORDER BY f.next_fetch_at ASC NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, user_id, created_at, updated_at, name, url, last_fetched_at, etag, last_modified, next_fetch_at, consecutive_failures, last_error, last_status, disabled_at, title, description, site_url, language, icon_url
+`
+
+func (q *Queries) ClaimNextFeedToFetch(ctx context.Context) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, claimNextFeedToFetch)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastStatus,
+		&i.DisabledAt,
+		&i.Title,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Language,
+		&i.IconUrl,
+	)
+	return i, err
+}
+
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, title, description, site_url, language, icon_url)
 VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 )
-RETURNING id, user_id, created_at, updated_at, name, url, last_fetched_at
+RETURNING id, user_id, created_at, updated_at, name, url, last_fetched_at, etag, last_modified, next_fetch_at, consecutive_failures, last_error, last_status, disabled_at, title, description, site_url, language, icon_url
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.UUID
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string
+	Url         string
+	UserID      uuid.UUID
+	Title       sql.NullString
+	Description sql.NullString
+	SiteUrl     sql.NullString
+	Language    sql.NullString
+	IconUrl     sql.NullString
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -37,6 +84,11 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.Title,
+		arg.Description,
+		arg.SiteUrl,
+		arg.Language,
+		arg.IconUrl,
 	)
 	var i Feed
 	err := row.Scan(
@@ -47,48 +99,56 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Name,
 		&i.Url,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastStatus,
+		&i.DisabledAt,
+		&i.Title,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Language,
+		&i.IconUrl,
 	)
 	return i, err
 }
 
-const deleteFeed = `-- name: DeleteFeed :exec
+const deleteFeed = `-- name: DeleteFeed :execrows
 DELETE FROM feeds
 WHERE url=$1
 `
 
-func (q *Queries) DeleteFeed(ctx context.Context, url string) error {
-	_, err := q.db.ExecContext(ctx, deleteFeed, url)
-	return err
+func (q *Queries) DeleteFeed(ctx context.Context, url string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeed, url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const getFeed = `-- name: GetFeed :one
-SELECT id, user_id, created_at, updated_at, name, url, last_fetched_at FROM feeds
+const enableFeed = `-- name: EnableFeed :execrows
+UPDATE feeds
+SET updated_at = NOW(), consecutive_failures = 0, last_error = NULL, disabled_at = NULL, next_fetch_at = NULL
 WHERE url = $1
 `
 
-func (q *Queries) GetFeed(ctx context.Context, url string) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getFeed, url)
-	var i Feed
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Url,
-		&i.LastFetchedAt,
-	)
-	return i, err
+func (q *Queries) EnableFeed(ctx context.Context, url string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, enableFeed, url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, user_id, created_at, updated_at, name, url, last_fetched_at
-FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
+const getFeed = `-- name: GetFeed :one
+SELECT id, user_id, created_at, updated_at, name, url, last_fetched_at, etag, last_modified, next_fetch_at, consecutive_failures, last_error, last_status, disabled_at, title, description, site_url, language, icon_url FROM feeds
+WHERE url = $1
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+func (q *Queries) GetFeed(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeed, url)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -98,23 +158,43 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Name,
 		&i.Url,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastStatus,
+		&i.DisabledAt,
+		&i.Title,
+		&i.Description,
+		&i.SiteUrl,
+		&i.Language,
+		&i.IconUrl,
 	)
 	return i, err
 }
 
 const getUserFeeds = `-- name: GetUserFeeds :many
-SELECT f.id, f.created_at, f.updated_at, f.name, f.url, u.name AS user_name
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.consecutive_failures, f.last_error, f.disabled_at,
+  f.title, f.description, f.site_url, f.language, u.name AS user_name
 FROM feeds AS f
 JOIN users AS u ON f.user_id = u.id
 `
 
 type GetUserFeedsRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserName  string
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	ConsecutiveFailures int32
+	LastError           sql.NullString
+	DisabledAt          sql.NullTime
+	Title               sql.NullString
+	Description         sql.NullString
+	SiteUrl             sql.NullString
+	Language            sql.NullString
+	UserName            string
 }
 
 func (q *Queries) GetUserFeeds(ctx context.Context) ([]GetUserFeedsRow, error) {
@@ -132,6 +212,13 @@ func (q *Queries) GetUserFeeds(ctx context.Context) ([]GetUserFeedsRow, error) {
 			&i.UpdatedAt,
 			&i.Name,
 			&i.Url,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.DisabledAt,
+			&i.Title,
+			&i.Description,
+			&i.SiteUrl,
+			&i.Language,
 			&i.UserName,
 		); err != nil {
 			return nil, err
@@ -147,13 +234,54 @@ func (q *Queries) GetUserFeeds(ctx context.Context) ([]GetUserFeedsRow, error) {
 	return items, nil
 }
 
+const markFeedFailed = `-- name: MarkFeedFailed :exec
+UPDATE feeds
+SET updated_at = NOW(), consecutive_failures = consecutive_failures + 1, last_error = $2, last_status = $3,
+    next_fetch_at = $4, disabled_at = $5
+WHERE id = $1
+`
+
+type MarkFeedFailedParams struct {
+	ID          uuid.UUID
+	LastError   sql.NullString
+	LastStatus  sql.NullInt32
+	NextFetchAt sql.NullTime
+	DisabledAt  sql.NullTime
+}
+
+func (q *Queries) MarkFeedFailed(ctx context.Context, arg MarkFeedFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFailed,
+		arg.ID,
+		arg.LastError,
+		arg.LastStatus,
+		arg.NextFetchAt,
+		arg.DisabledAt,
+	)
+	return err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 UPDATE feeds
-SET last_fetched_at = NOW(), updated_at = NOW()
+SET last_fetched_at = NOW(), updated_at = NOW(), etag = $2, last_modified = $3, next_fetch_at = $4,
+    last_status = $5, consecutive_failures = 0, last_error = NULL
 WHERE id = $1
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+type MarkFeedFetchedParams struct {
+	ID           uuid.UUID
+	Etag         sql.NullString
+	LastModified sql.NullString
+	NextFetchAt  sql.NullTime
+	LastStatus   sql.NullInt32
+}
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched,
+		arg.ID,
+		arg.Etag,
+		arg.LastModified,
+		arg.NextFetchAt,
+		arg.LastStatus,
+	)
 	return err
 }
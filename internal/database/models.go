@@ -11,14 +11,37 @@ import (
 	"github.com/google/uuid"
 )
 
+type Bookmark struct {
+	UserID      uuid.UUID
+	PostID      uuid.UUID
+	CreatedAt   time.Time
+	FeedName    string
+	Title       string
+	Url         string
+	Description string
+	PublishedAt time.Time
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	UserID        uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	LastFetchedAt sql.NullTime
+	ID                  uuid.UUID
+	UserID              uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	NextFetchAt         sql.NullTime
+	ConsecutiveFailures int32
+	LastError           sql.NullString
+	LastStatus          sql.NullInt32
+	DisabledAt          sql.NullTime
+	Title               sql.NullString
+	Description         sql.NullString
+	SiteUrl             sql.NullString
+	Language            sql.NullString
+	IconUrl             sql.NullString
 }
 
 type FeedFollow struct {
@@ -27,17 +50,26 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Folder    sql.NullString
 }
 
 type Post struct {
-	ID          uuid.UUID
-	FeedID      uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description string
-	PublishedAt time.Time
+	ID           uuid.UUID
+	FeedID       uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	Guid         string
+	SearchVector interface{}
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
 }
 
 type User struct {
@@ -7,13 +7,182 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, feed_id, created_at, updated_at, title, url, description, published_at)
+const getPost = `-- name: GetPost :one
+SELECT id, feed_id, created_at, updated_at, title, url, description, published_at, guid, search_vector FROM posts
+WHERE id = $1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.Guid,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const getPostsFromUser = `-- name: GetPostsFromUser :many
+SELECT p.id, p.feed_id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.guid, p.search_vector, pr.read_at
+FROM posts AS p
+INNER JOIN feed_follows AS ff ON p.feed_id = ff.feed_id
+LEFT JOIN post_reads AS pr ON p.id = pr.post_id AND pr.user_id = ff.user_id
+WHERE ff.user_id = $1
+    AND ($2::boolean OR pr.post_id IS NULL)
+    AND ($3::uuid IS NULL OR p.feed_id = $3)
+    AND ($4::timestamp IS NULL OR p.published_at >= $4)
+    AND ($5::timestamp IS NULL OR p.published_at < $5)
+    AND ($6::timestamp IS NULL
+        OR ($7::boolean AND (p.published_at, p.id) > ($6, $8::uuid))
+        OR (NOT $7 AND (p.published_at, p.id) < ($6, $8)))
+ORDER BY
+    CASE WHEN $7 THEN p.published_at END ASC,
+    CASE WHEN $7 THEN p.id END ASC,
+    p.published_at DESC, p.id DESC
+LIMIT $9 OFFSET $10
+`
+
+type GetPostsFromUserParams struct {
+	UserID           uuid.UUID
+	IncludeRead      bool
+	FeedID           uuid.NullUUID
+	Since            sql.NullTime
+	Until            sql.NullTime
+	AfterPublishedAt sql.NullTime
+	OldestFirst      bool
+	AfterID          uuid.NullUUID
+	Limit            int32
+	Offset           int32
+}
+
+type GetPostsFromUserRow struct {
+	ID           uuid.UUID
+	FeedID       uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	Guid         string
+	SearchVector interface{}
+	ReadAt       sql.NullTime
+}
+
+func (q *Queries) GetPostsFromUser(ctx context.Context, arg GetPostsFromUserParams) ([]GetPostsFromUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsFromUser,
+		arg.UserID,
+		arg.IncludeRead,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.AfterPublishedAt,
+		arg.OldestFirst,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsFromUserRow
+	for rows.Next() {
+		var i GetPostsFromUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.Guid,
+			&i.SearchVector,
+			&i.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentPublishedDates = `-- name: GetRecentPublishedDates :many
+SELECT published_at
+FROM posts
+WHERE feed_id = $1
+ORDER BY published_at DESC
+LIMIT 10
+`
+
+func (q *Queries) GetRecentPublishedDates(ctx context.Context, feedID uuid.UUID) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPublishedDates, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var published_at time.Time
+		if err := rows.Scan(&published_at); err != nil {
+			return nil, err
+		}
+		items = append(items, published_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rekeyLegacyPost = `-- name: RekeyLegacyPost :execrows
+UPDATE posts
+SET guid = $1
+WHERE feed_id = $2 AND guid = $3 AND url = $3
+    AND NOT EXISTS (SELECT 1 FROM posts AS p WHERE p.feed_id = $2 AND p.guid = $1)
+`
+
+type RekeyLegacyPostParams struct {
+	Guid   string
+	FeedID uuid.UUID
+	Url    string
+}
+
+func (q *Queries) RekeyLegacyPost(ctx context.Context, arg RekeyLegacyPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rekeyLegacyPost, arg.Guid, arg.FeedID, arg.Url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertPost = `-- name: UpsertPost :one
+INSERT INTO posts (id, feed_id, created_at, updated_at, title, url, description, published_at, guid)
 VALUES (
     gen_random_uuid (),
     $1,
@@ -22,25 +191,32 @@ VALUES (
     $2,
     $3,
     $4,
-    $5
-) RETURNING id, feed_id, created_at, updated_at, title, url, description, published_at
+    $5,
+    $6
+)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title, url = EXCLUDED.url, description = EXCLUDED.description, updated_at = NOW()
+WHERE posts.title <> EXCLUDED.title OR posts.url <> EXCLUDED.url OR posts.description <> EXCLUDED.description
+RETURNING id, feed_id, created_at, updated_at, title, url, description, published_at, guid, search_vector
 `
 
-type CreatePostParams struct {
+type UpsertPostParams struct {
 	FeedID      uuid.UUID
 	Title       string
 	Url         string
 	Description string
 	PublishedAt time.Time
+	Guid        string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
 		arg.FeedID,
 		arg.Title,
 		arg.Url,
 		arg.Description,
 		arg.PublishedAt,
+		arg.Guid,
 	)
 	var i Post
 	err := row.Scan(
@@ -52,58 +228,61 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Url,
 		&i.Description,
 		&i.PublishedAt,
+		&i.Guid,
+		&i.SearchVector,
 	)
 	return i, err
 }
 
-const getPostsFromUser = `-- name: GetPostsFromUser :many
-WITH userposts AS (
-    SELECT ff.feed_id FROM feed_follows as ff WHERE ff.user_id = $1
-)
-SELECT id, p.feed_id, created_at, updated_at, title, url, description, published_at, userposts.feed_id
+const searchPosts = `-- name: SearchPosts :many
+SELECT p.id, p.title, p.url, p.published_at, f.name AS feed_name,
+    ts_headline('english', p.title, q, 'HighlightAll=true, StartSel=**, StopSel=**') AS title_snippet,
+    ts_headline('english', p.description, q, 'MaxFragments=2, MaxWords=20, MinWords=8, StartSel=**, StopSel=**') AS snippet,
+    ts_rank(p.search_vector, q) AS rank
 FROM posts AS p
-INNER JOIN userposts ON p.feed_id = userposts.feed_id
-ORDER BY published_at DESC
-LIMIT $2 OFFSET $3
+INNER JOIN feeds AS f ON p.feed_id = f.id
+INNER JOIN feed_follows AS ff ON f.id = ff.feed_id
+CROSS JOIN websearch_to_tsquery('english', $1) AS q
+WHERE ff.user_id = $2 AND p.search_vector @@ q
+ORDER BY rank DESC, p.published_at DESC
+LIMIT $3
 `
 
-type GetPostsFromUserParams struct {
+type SearchPostsParams struct {
+	Query  string
 	UserID uuid.UUID
 	Limit  int32
-	Offset int32
 }
 
-type GetPostsFromUserRow struct {
-	ID          uuid.UUID
-	FeedID      uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description string
-	PublishedAt time.Time
-	FeedID_2    uuid.UUID
+type SearchPostsRow struct {
+	ID           uuid.UUID
+	Title        string
+	Url          string
+	PublishedAt  time.Time
+	FeedName     string
+	TitleSnippet string
+	Snippet      string
+	Rank         float32
 }
 
-func (q *Queries) GetPostsFromUser(ctx context.Context, arg GetPostsFromUserParams) ([]GetPostsFromUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsFromUser, arg.UserID, arg.Limit, arg.Offset)
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts, arg.Query, arg.UserID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetPostsFromUserRow
+	var items []SearchPostsRow
 	for rows.Next() {
-		var i GetPostsFromUserRow
+		var i SearchPostsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.FeedID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
 			&i.Title,
 			&i.Url,
-			&i.Description,
 			&i.PublishedAt,
-			&i.FeedID_2,
+			&i.FeedName,
+			&i.TitleSnippet,
+			&i.Snippet,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
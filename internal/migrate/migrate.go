@@ -0,0 +1,93 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/charlesaraya/gator/internal/config"
+	"github.com/charlesaraya/gator/internal/sql/schema"
+	sqliteschema "github.com/charlesaraya/gator/internal/sql/sqlite/schema"
+	"github.com/pressly/goose/v3"
+)
+
+func newProvider(db *sql.DB, driver string) (*goose.Provider, error) {
+	var provider *goose.Provider
+	var err error
+	switch driver {
+	case config.POSTGRES_DRIVER:
+		provider, err = goose.NewProvider(goose.DialectPostgres, db, schema.FS)
+	case config.SQLITE_DRIVER:
+		provider, err = goose.NewProvider(goose.DialectSQLite3, db, sqliteschema.FS)
+	default:
+		return nil, fmt.Errorf("unsupported database driver '%s'", driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration provider: %w", err)
+	}
+	return provider, nil
+}
+
+// Up applies all pending migrations.
+func Up(ctx context.Context, db *sql.DB, driver string) ([]*goose.MigrationResult, error) {
+	provider, err := newProvider(db, driver)
+	if err != nil {
+		return nil, err
+	}
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return results, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return results, nil
+}
+
+// Down rolls back the most recently applied migration.
+func Down(ctx context.Context, db *sql.DB, driver string) (*goose.MigrationResult, error) {
+	provider, err := newProvider(db, driver)
+	if err != nil {
+		return nil, err
+	}
+	result, err := provider.Down(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to roll back migration: %w", err)
+	}
+	return result, nil
+}
+
+// Status reports every embedded migration and whether it has been applied.
+func Status(ctx context.Context, db *sql.DB, driver string) ([]*goose.MigrationStatus, error) {
+	provider, err := newProvider(db, driver)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration status: %w", err)
+	}
+	return statuses, nil
+}
+
+// Versions returns the schema version of the database and the latest embedded migration version.
+func Versions(ctx context.Context, db *sql.DB, driver string) (current, latest int64, err error) {
+	provider, err := newProvider(db, driver)
+	if err != nil {
+		return 0, 0, err
+	}
+	current, latest, err = provider.GetVersions(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get schema versions: %w", err)
+	}
+	return current, latest, nil
+}
+
+// Check returns an error if the database schema is behind the embedded migrations.
+func Check(ctx context.Context, db *sql.DB, driver string) error {
+	current, latest, err := Versions(ctx, db, driver)
+	if err != nil {
+		return err
+	}
+	if current < latest {
+		return fmt.Errorf("database schema is at version %d but gator requires version %d, run 'gator migrate up'", current, latest)
+	}
+	return nil
+}
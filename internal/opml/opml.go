@@ -0,0 +1,189 @@
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline is either a feed subscription, when it has an xmlUrl, or a category grouping other outlines.
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// UnmarshalXML reads attribute names case-insensitively, since OPML 1.0 exporters disagree on xmlUrl's case.
+func (o *Outline) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch strings.ToLower(attr.Name.Local) {
+		case "text":
+			o.Text = attr.Value
+		case "title":
+			o.Title = attr.Value
+		case "type":
+			o.Type = attr.Value
+		case "xmlurl":
+			o.XMLURL = attr.Value
+		case "htmlurl":
+			o.HTMLURL = attr.Value
+		}
+	}
+	var children struct {
+		Outlines []Outline `xml:"outline"`
+	}
+	if err := d.DecodeElement(&children, &start); err != nil {
+		return err
+	}
+	o.Outlines = children.Outlines
+	return nil
+}
+
+// Name returns the outline's title, falling back to its text.
+func (o Outline) Name() string {
+	if title := strings.TrimSpace(o.Title); title != "" {
+		return title
+	}
+	return strings.TrimSpace(o.Text)
+}
+
+// Feed is a subscription found in an OPML document, along with the categories it's nested in.
+type Feed struct {
+	Name    string
+	URL     string
+	SiteURL string
+	// Category is the path of the enclosing category outlines, joined with "/", or "" at the top level.
+	// Backslashes and slashes within a category's name are escaped with a backslash.
+	Category string
+}
+
+var categoryEscaper = strings.NewReplacer(`\`, `\\`, "/", `\/`)
+
+func joinCategory(names []string) string {
+	escaped := make([]string, len(names))
+	for i, name := range names {
+		escaped[i] = categoryEscaper.Replace(name)
+	}
+	return strings.Join(escaped, "/")
+}
+
+// splitCategory reverses joinCategory.
+func splitCategory(category string) []string {
+	var names []string
+	var name strings.Builder
+	for i := 0; i < len(category); i++ {
+		switch {
+		case category[i] == '\\' && i+1 < len(category):
+			i++
+			name.WriteByte(category[i])
+		case category[i] == '/':
+			names = append(names, name.String())
+			name.Reset()
+		default:
+			name.WriteByte(category[i])
+		}
+	}
+	return append(names, name.String())
+}
+
+func Parse(r io.Reader) (*OPML, error) {
+	doc := OPML{}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal OPML document: %w", err)
+	}
+	return &doc, nil
+}
+
+// Feeds flattens the document's outlines into the feeds they subscribe to, in document order.
+// rss outlines missing their xmlUrl are returned with an empty URL, so callers can report them.
+func (doc *OPML) Feeds() []Feed {
+	var feeds []Feed
+	var walk func(outlines []Outline, category []string)
+	walk = func(outlines []Outline, category []string) {
+		for _, outline := range outlines {
+			switch {
+			case outline.XMLURL != "":
+				feeds = append(feeds, Feed{
+					Name:     outline.Name(),
+					URL:      strings.TrimSpace(outline.XMLURL),
+					SiteURL:  strings.TrimSpace(outline.HTMLURL),
+					Category: joinCategory(category),
+				})
+			case len(outline.Outlines) > 0:
+				walk(outline.Outlines, append(category[:len(category):len(category)], outline.Name()))
+			case strings.EqualFold(outline.Type, "rss"):
+				feeds = append(feeds, Feed{Name: outline.Name(), Category: joinCategory(category)})
+			}
+		}
+	}
+	walk(doc.Body.Outlines, nil)
+	return feeds
+}
+
+// New builds an OPML 2.0 document subscribing to feeds, nesting them in outlines for their categories.
+func New(title string, feeds []Feed) *OPML {
+	doc := &OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, feed := range feeds {
+		outlines := &doc.Body.Outlines
+		if feed.Category != "" {
+			for _, name := range splitCategory(feed.Category) {
+				i := slices.IndexFunc(*outlines, func(o Outline) bool { return o.XMLURL == "" && o.Text == name })
+				if i < 0 {
+					*outlines = append(*outlines, Outline{Text: name, Title: name})
+					i = len(*outlines) - 1
+				}
+				outlines = &(*outlines)[i].Outlines
+			}
+		}
+		*outlines = append(*outlines, Outline{
+			Text:    feed.Name,
+			Title:   feed.Name,
+			Type:    "rss",
+			XMLURL:  feed.URL,
+			HTMLURL: feed.SiteURL,
+		})
+	}
+	return doc
+}
+
+func (doc *OPML) Write(w io.Writer) error {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal OPML document: %w", err)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if _, err := w.Write(append(out, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
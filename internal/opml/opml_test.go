@@ -0,0 +1,61 @@
+package opml
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCategoryRoundTrip(t *testing.T) {
+	const body = `<?xml version="1.0"?>
+<opml version="2.0"><head><title>Subscriptions</title></head><body>
+<outline text="News/Politics">
+  <outline text="Daily" type="rss" xmlUrl="https://example.com/daily.xml"/>
+  <outline text="Local">
+    <outline text="Town" type="rss" xmlUrl="https://example.com/town.xml"/>
+  </outline>
+</outline>
+<outline text="News"><outline text="Politics"><outline text="Weekly" type="rss" xmlUrl="https://example.com/weekly.xml"/></outline></outline>
+<outline text="C:\Feeds"><outline text="Windows" type="rss" xmlUrl="https://example.com/windows.xml"/></outline>
+<outline text="Top" type="rss" xmlUrl="https://example.com/top.xml"/>
+</body></opml>`
+	want := []Feed{
+		{Name: "Daily", URL: "https://example.com/daily.xml", Category: `News\/Politics`},
+		{Name: "Town", URL: "https://example.com/town.xml", Category: `News\/Politics/Local`},
+		{Name: "Weekly", URL: "https://example.com/weekly.xml", Category: "News/Politics"},
+		{Name: "Windows", URL: "https://example.com/windows.xml", Category: `C:\\Feeds`},
+		{Name: "Top", URL: "https://example.com/top.xml"},
+	}
+
+	doc, err := Parse(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if got := doc.Feeds(); !slices.Equal(got, want) {
+		t.Fatalf("Feeds() = %+v, want %+v", got, want)
+	}
+
+	var out bytes.Buffer
+	if err := New("Subscriptions", want).Write(&out); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	written, err := Parse(&out)
+	if err != nil {
+		t.Fatalf("Parse of the written document returned error: %v", err)
+	}
+	if got := written.Feeds(); !slices.Equal(got, want) {
+		t.Errorf("Feeds() after a round trip = %+v, want %+v", got, want)
+	}
+	if names := outlineNames(written.Body.Outlines); !slices.Equal(names, []string{"News/Politics", "News", `C:\Feeds`, "Top"}) {
+		t.Errorf("top level outlines = %q", names)
+	}
+}
+
+func outlineNames(outlines []Outline) []string {
+	names := make([]string, len(outlines))
+	for i, outline := range outlines {
+		names[i] = outline.Name()
+	}
+	return names
+}
@@ -0,0 +1,87 @@
+package rss
+
+import (
+	"strings"
+)
+
+type AtomFeed struct {
+	Lang     string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Title    AtomText    `xml:"title"`
+	Subtitle AtomText    `xml:"subtitle"`
+	Links    []AtomLink  `xml:"link"`
+	Icon     string      `xml:"icon"`
+	Logo     string      `xml:"logo"`
+	Updated  string      `xml:"updated"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID        string     `xml:"id"`
+	Title     AtomText   `xml:"title"`
+	Links     []AtomLink `xml:"link"`
+	Summary   AtomText   `xml:"summary"`
+	Content   AtomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// AtomText holds an Atom text construct, which may be plain text, escaped html or inline xhtml.
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// alternateLink returns the href of the rel="alternate" link, falling back to the first link.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// toRSS maps the Atom feed into the common RSSFeed model.
+func (a *AtomFeed) toRSS() *RSSFeed {
+	feed := RSSFeed{}
+	feed.Channel.Title = a.Title.String()
+	feed.Channel.Link = alternateLink(a.Links)
+	feed.Channel.Description = a.Subtitle.String()
+	feed.Channel.Language = a.Lang
+	feed.Channel.Image.URL = a.Icon
+	if feed.Channel.Image.URL == "" {
+		feed.Channel.Image.URL = a.Logo
+	}
+	for _, entry := range a.Entries {
+		item := RSSItem{
+			GUID:        entry.ID,
+			Title:       entry.Title.String(),
+			Link:        alternateLink(entry.Links),
+			Description: entry.Summary.String(),
+			PubDate:     entry.Published,
+			Updated:     entry.Updated,
+		}
+		if item.Description == "" {
+			item.Description = entry.Content.String()
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	return &feed
+}
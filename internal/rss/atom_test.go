@@ -0,0 +1,127 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAtom(t *testing.T) {
+	const header = `<?xml version="1.0" encoding="utf-8"?>` + "\n"
+	tests := []struct {
+		name string
+		body string
+		// check inspects the feed parsed from body.
+		check func(t *testing.T, feed *RSSFeed)
+	}{
+		{
+			name: "feed metadata",
+			body: header + `<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en-GB">
+<title type="html">Tom &amp;amp; Jerry</title>
+<subtitle>Cartoons</subtitle>
+<link rel="self" href="https://example.com/feed.atom"/>
+<link rel="alternate" type="text/html" href="https://example.com/"/>
+<icon>https://example.com/icon.png</icon>
+<logo>https://example.com/logo.png</logo>
+</feed>`,
+			check: func(t *testing.T, feed *RSSFeed) {
+				channel := feed.Channel
+				assertEqual(t, "format", feed.Format, "Atom")
+				assertEqual(t, "title", channel.Title, "Tom &amp; Jerry")
+				assertEqual(t, "description", channel.Description, "Cartoons")
+				assertEqual(t, "link", channel.Link, "https://example.com/")
+				assertEqual(t, "language", channel.Language, "en-GB")
+				assertEqual(t, "icon", channel.Image.URL, "https://example.com/icon.png")
+			},
+		},
+		{
+			name: "logo when there is no icon",
+			body: header + `<feed xmlns="http://www.w3.org/2005/Atom"><title>Logo</title><logo>https://example.com/logo.png</logo></feed>`,
+			check: func(t *testing.T, feed *RSSFeed) {
+				assertEqual(t, "icon", feed.Channel.Image.URL, "https://example.com/logo.png")
+			},
+		},
+		{
+			name: "link selection",
+			body: header + `<feed xmlns="http://www.w3.org/2005/Atom"><title>Links</title>
+<entry><id>1</id><link rel="self" href="https://example.com/1.atom"/><link rel="alternate" href="https://example.com/1"/></entry>
+<entry><id>2</id><link rel="edit" href="https://example.com/2/edit"/><link href="https://example.com/2"/></entry>
+<entry><id>3</id><link rel="enclosure" href="https://example.com/3.mp3"/><link rel="replies" href="https://example.com/3/comments"/></entry>
+<entry><id>4</id></entry>
+</feed>`,
+			check: func(t *testing.T, feed *RSSFeed) {
+				items := feed.Channel.Items
+				if len(items) != 4 {
+					t.Fatalf("got %d items, want 4", len(items))
+				}
+				assertEqual(t, "alternate link", items[0].Link, "https://example.com/1")
+				assertEqual(t, "link without rel", items[1].Link, "https://example.com/2")
+				assertEqual(t, "first link", items[2].Link, "https://example.com/3.mp3")
+				assertEqual(t, "no link", items[3].Link, "")
+			},
+		},
+		{
+			name: "text, html and xhtml content",
+			body: header + `<feed xmlns="http://www.w3.org/2005/Atom"><title>Content</title>
+<entry><id>1</id><title>  Plain  </title><summary>A summary</summary><content>The content</content></entry>
+<entry><id>2</id><title>Html</title><content type="html">&lt;p&gt;Escaped &amp;amp; html&lt;/p&gt;</content></entry>
+<entry><id>3</id><title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">X<em>html</em></div></title><content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Inline</p></div></content></entry>
+</feed>`,
+			check: func(t *testing.T, feed *RSSFeed) {
+				items := feed.Channel.Items
+				if len(items) != 3 {
+					t.Fatalf("got %d items, want 3", len(items))
+				}
+				assertEqual(t, "title", items[0].Title, "Plain")
+				assertEqual(t, "summary over content", items[0].Description, "A summary")
+				assertEqual(t, "html content", items[1].Description, "<p>Escaped &amp; html</p>")
+				assertEqual(t, "xhtml title", items[2].Title, `<div xmlns="http://www.w3.org/1999/xhtml">X<em>html</em></div>`)
+				assertEqual(t, "xhtml content", items[2].Description, `<div xmlns="http://www.w3.org/1999/xhtml"><p>Inline</p></div>`)
+			},
+		},
+		{
+			name: "published and updated dates",
+			body: header + `<feed xmlns="http://www.w3.org/2005/Atom"><title>Dates</title>
+<entry><id>1</id><published>2024-08-01T10:00:00Z</published><updated>2024-08-02T10:00:00Z</updated></entry>
+<entry><id>2</id><updated>2024-08-02T10:00:00+02:00</updated></entry>
+<entry><id>3</id></entry>
+</feed>`,
+			check: func(t *testing.T, feed *RSSFeed) {
+				items := feed.Channel.Items
+				if len(items) != 3 {
+					t.Fatalf("got %d items, want 3", len(items))
+				}
+				fetchedAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+				for i, want := range []time.Time{
+					time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC),
+					time.Date(2024, 8, 2, 8, 0, 0, 0, time.UTC),
+					fetchedAt,
+				} {
+					got, err := items[i].PublishedAt(fetchedAt)
+					if err != nil {
+						t.Fatalf("item %d: PublishedAt returned error: %v", i+1, err)
+					}
+					if !got.Equal(want) {
+						t.Errorf("item %d: PublishedAt = %v, want %v", i+1, got, want)
+					}
+				}
+				assertEqual(t, "guid", feed.Channel.Items[0].GUID, "1")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := parseFeed([]byte(tt.body), "application/atom+xml")
+			if err != nil {
+				t.Fatalf("parseFeed returned error: %v", err)
+			}
+			tt.check(t, feed)
+		})
+	}
+}
+
+func assertEqual(t *testing.T, what, got, want string) {
+	t.Helper()
+	if got != want {
+		t.Errorf("%s = %q, want %q", what, got, want)
+	}
+}
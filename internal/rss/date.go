@@ -0,0 +1,166 @@
+package rss
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// dateLayouts lists the publication date formats found in real-world feeds, tried in order.
+// Leading weekday names are stripped before parsing, so layouts don't include them.
+var dateLayouts = []string{
+	// RFC 822 / RFC 1123 and their common variations
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 MST -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04 MST",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 MST",
+	"2 January 2006 15:04 -0700",
+	"2 January 2006 15:04 MST",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 2006",
+	"2 January 2006",
+	"2-Jan-06 15:04:05 MST",
+	"2-Jan-2006 15:04:05 MST",
+	"2-Jan-2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04:05 MST",
+	"Jan 2, 2006 3:04 PM MST",
+	"Jan 2, 2006",
+	"January 2, 2006 15:04:05 -0700",
+	"January 2, 2006",
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	// ISO 8601 / RFC 3339 / W3CDTF and their common variations
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01",
+	time.DateOnly,
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// zoneOffsets resolves the zone abbreviations commonly used in feeds, which time.Parse
+// would otherwise read as UTC unless they happen to match the local zone.
+var zoneOffsets = map[string]int{
+	"UT":   0,
+	"UTC":  0,
+	"GMT":  0,
+	"EST":  -5 * 60 * 60,
+	"EDT":  -4 * 60 * 60,
+	"CST":  -6 * 60 * 60,
+	"CDT":  -5 * 60 * 60,
+	"MST":  -7 * 60 * 60,
+	"MDT":  -6 * 60 * 60,
+	"PST":  -8 * 60 * 60,
+	"PDT":  -7 * 60 * 60,
+	"AKST": -9 * 60 * 60,
+	"AKDT": -8 * 60 * 60,
+	"HST":  -10 * 60 * 60,
+	"BST":  1 * 60 * 60,
+	"IST":  int(5.5 * 60 * 60),
+	"CET":  1 * 60 * 60,
+	"CEST": 2 * 60 * 60,
+	"EET":  2 * 60 * 60,
+	"EEST": 3 * 60 * 60,
+	"MSK":  3 * 60 * 60,
+	"JST":  9 * 60 * 60,
+	"KST":  9 * 60 * 60,
+	"AEST": 10 * 60 * 60,
+	"AEDT": 11 * 60 * 60,
+	"NZST": 12 * 60 * 60,
+	"NZDT": 13 * 60 * 60,
+}
+
+// ParseDate parses a feed publication date in any of the known real-world formats.
+func ParseDate(value string) (time.Time, error) {
+	value = normalizeDate(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	if militaryZoneRe.MatchString(value) {
+		return time.Time{}, fmt.Errorf("ambiguous military time zone in '%s'", value)
+	}
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		return resolveZone(t)
+	}
+	return time.Time{}, fmt.Errorf("unknown date format: '%s'", value)
+}
+
+var (
+	// gmtOffsetRe matches zones given as an offset from GMT or UTC, such as "GMT+2" or "UTC-03:30".
+	gmtOffsetRe = regexp.MustCompile(` (?:GMT|UTC?)([+-])(\d{1,2})(?::?(\d{2}))?$`)
+	// militaryZoneRe matches single-letter military zones, of which only Z has an unambiguous meaning.
+	militaryZoneRe = regexp.MustCompile(` [A-IK-Z]$`)
+)
+
+// normalizeDate collapses whitespace, drops a leading weekday name and any trailing comment such as "(UTC)".
+func normalizeDate(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if i := strings.Index(value, ","); i > 0 && isWord(value[:i]) {
+		value = strings.TrimSpace(value[i+1:])
+	}
+	if i := strings.Index(value, " ("); i > 0 && strings.HasSuffix(value, ")") {
+		value = value[:i]
+	}
+	if strings.HasSuffix(value, " UT") {
+		value += "C"
+	}
+	if match := gmtOffsetRe.FindStringSubmatch(value); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi(match[3])
+		value = fmt.Sprintf("%s %s%02d%02d", value[:len(value)-len(match[0])], match[1], hours, minutes)
+	}
+	if strings.HasSuffix(value, " Z") {
+		value = strings.TrimSuffix(value, "Z") + "+0000"
+	}
+	return value
+}
+
+func isWord(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveZone applies the offset of the zone abbreviation t was parsed with. Unknown abbreviations are
+// an error rather than silently read as UTC.
+func resolveZone(t time.Time) (time.Time, error) {
+	name, offset := t.Zone()
+	if offset != 0 || name == "" || name == "UTC" {
+		return t, nil
+	}
+	zoneOffset, ok := zoneOffsets[strings.ToUpper(name)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown time zone '%s'", name)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, zoneOffset)), nil
+}
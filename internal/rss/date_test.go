@@ -0,0 +1,126 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	utc := time.UTC
+	zone := func(hours, minutes int) *time.Location {
+		return time.FixedZone("", hours*60*60+minutes*60)
+	}
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		// RFC 822 / RFC 1123 and their common variations
+		{"Sat, 07 Sep 2002 00:00:01 +0200", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(2, 0))},
+		{"Sat, 07 Sep 2002 00:00:01 +02:00", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(2, 0))},
+		{"Sat, 07 Sep 2002 00:00:01 GMT", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"Sat, 07 Sep 2002 00:00:01 EST", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(-5, 0))},
+		{"Sat, 07 Sep 2002 00:00:01 EDT -0400", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(-4, 0))},
+		{"7 Sep 2002 13:45 -0700", time.Date(2002, 9, 7, 13, 45, 0, 0, zone(-7, 0))},
+		{"7 Sep 2002 13:45 PDT", time.Date(2002, 9, 7, 13, 45, 0, 0, zone(-7, 0))},
+		{"Sat, 07 Sep 02 00:00:01 -0000", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"07 Sep 02 00:00:01 UT", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"07 Sep 02 13:45 +0530", time.Date(2002, 9, 7, 13, 45, 0, 0, zone(5, 30))},
+		{"07 Sep 02 13:45 CET", time.Date(2002, 9, 7, 13, 45, 0, 0, zone(1, 0))},
+		{"Saturday, 7 September 2002 00:00:01 +0900", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(9, 0))},
+		{"7 September 2002 00:00:01 JST", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(9, 0))},
+		{"7 September 2002 13:45 -0300", time.Date(2002, 9, 7, 13, 45, 0, 0, zone(-3, 0))},
+		{"7 September 2002 13:45 UTC", time.Date(2002, 9, 7, 13, 45, 0, 0, utc)},
+		{"Sat, 07 Sep 2002 00:00:01", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"Sat, 07 Sep 2002 13:45", time.Date(2002, 9, 7, 13, 45, 0, 0, utc)},
+		{"07 Sep 2002", time.Date(2002, 9, 7, 0, 0, 0, 0, utc)},
+		{"7 September 2002", time.Date(2002, 9, 7, 0, 0, 0, 0, utc)},
+		{"7-Sep-02 00:00:01 GMT", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"7-Sep-2002 00:00:01 BST", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(1, 0))},
+		{"7-Sep-2002 00:00:01 +0100", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(1, 0))},
+		{"Sep 7, 2002 00:00:01 +1000", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(10, 0))},
+		{"Sep 7, 2002 00:00:01 AEDT", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(11, 0))},
+		{"Sep 7, 2002 3:04 PM CST", time.Date(2002, 9, 7, 15, 4, 0, 0, zone(-6, 0))},
+		{"Sep 7, 2002", time.Date(2002, 9, 7, 0, 0, 0, 0, utc)},
+		{"September 7, 2002 00:00:01 +0000", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"September 7, 2002", time.Date(2002, 9, 7, 0, 0, 0, 0, utc)},
+		{"Sat Sep  7 00:00:01 2002", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"Sat Sep  7 00:00:01 MSK 2002", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(3, 0))},
+		{"Sat Sep 07 00:00:01 -0800 2002", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(-8, 0))},
+		// ISO 8601 / RFC 3339 / W3CDTF and their common variations
+		{"2002-09-07T00:00:01Z", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"2002-09-07T00:00:01.5+02:00", time.Date(2002, 9, 7, 0, 0, 1, 5e8, zone(2, 0))},
+		{"2002-09-07T00:00:01+0200", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(2, 0))},
+		{"2002-09-07T00:00:01", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"2002-09-07T13:45-05:00", time.Date(2002, 9, 7, 13, 45, 0, 0, zone(-5, 0))},
+		{"2002-09-07T13:45", time.Date(2002, 9, 7, 13, 45, 0, 0, utc)},
+		{"2002-09-07 00:00:01Z", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"2002-09-07 00:00:01 -0600", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(-6, 0))},
+		{"2002-09-07 00:00:01 -06:00", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(-6, 0))},
+		{"2002-09-07 00:00:01 NZST", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(12, 0))},
+		{"2002-09-07 00:00:01", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"2002-09-07 13:45", time.Date(2002, 9, 7, 13, 45, 0, 0, utc)},
+		{"2002-09", time.Date(2002, 9, 1, 0, 0, 0, 0, utc)},
+		{"2002-09-07", time.Date(2002, 9, 7, 0, 0, 0, 0, utc)},
+		{"2002/09/07 00:00:01", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"2002/09/07", time.Date(2002, 9, 7, 0, 0, 0, 0, utc)},
+		// zones given as an offset from GMT or UTC, and the single-letter Z
+		{"Sat, 07 Sep 2002 00:00:01 GMT+2", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(2, 0))},
+		{"Sat, 07 Sep 2002 00:00:01 GMT-0330", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(-3, -30))},
+		{"Sat, 07 Sep 2002 00:00:01 UTC+05:30", time.Date(2002, 9, 7, 0, 0, 1, 0, zone(5, 30))},
+		{"Thu, 01 Jan 1970 00:00:00 Z", time.Date(1970, 1, 1, 0, 0, 0, 0, utc)},
+		// whitespace and comments
+		{"  Sat,  07 Sep 2002\n00:00:01 GMT  ", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+		{"Sat, 07 Sep 2002 00:00:01 +0000 (UTC)", time.Date(2002, 9, 7, 0, 0, 1, 0, utc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDate(tt.value)
+			if err != nil {
+				t.Fatalf("ParseDate(%q) returned error: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			_, gotOffset := got.Zone()
+			_, wantOffset := tt.want.Zone()
+			if gotOffset != wantOffset {
+				t.Errorf("ParseDate(%q) offset = %d, want %d", tt.value, gotOffset, wantOffset)
+			}
+		})
+	}
+}
+
+func TestParseDateErrors(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"yesterday",
+		"Sat, 07 Sep 2002 00:00:01 XYZ",
+		"Thu, 01 Jan 1970 00:00:00 A",
+		"Thu, 01 Jan 1970 00:00:00 N",
+		"2002-13-45",
+	} {
+		t.Run(value, func(t *testing.T) {
+			if got, err := ParseDate(value); err == nil {
+				t.Errorf("ParseDate(%q) = %v, want an error", value, got)
+			}
+		})
+	}
+}
+
+// TestDateLayoutsRoundTrip makes sure every layout is reachable, by formatting a date with it and parsing it back.
+func TestDateLayoutsRoundTrip(t *testing.T) {
+	want := time.Date(2002, 9, 7, 13, 45, 1, 0, time.FixedZone("CEST", 2*60*60))
+	for _, layout := range dateLayouts {
+		t.Run(layout, func(t *testing.T) {
+			value := want.Format(layout)
+			got, err := ParseDate(value)
+			if err != nil {
+				t.Fatalf("ParseDate(%q) returned error: %v", value, err)
+			}
+			// layouts without seconds, time or zone lose precision, so compare what the layout kept
+			if got.Format(layout) != value {
+				t.Errorf("ParseDate(%q) = %v, which formats as %q", value, got, got.Format(layout))
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DiscoveredFeed is a feed found at, or advertised by, a web page.
+type DiscoveredFeed struct {
+	URL   string
+	Title string
+	// Feed is the parsed feed when discovery had to download it, or nil for feeds the page links to.
+	Feed *RSSFeed
+}
+
+var (
+	feedMediaTypes = []string{"application/rss+xml", "application/atom+xml", "application/feed+json"}
+	// xmlMediaTypes are served for feeds as well, so a document of these types that fails to parse is a broken feed.
+	xmlMediaTypes = []string{"application/xml", "text/xml", "application/rdf+xml"}
+	// commonFeedPaths are tried on the page's site when it doesn't advertise any feed.
+	commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml"}
+
+	linkTagRe   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	htmlAttrRe  = regexp.MustCompile(`(?s)([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+))`)
+	baseHrefRe  = regexp.MustCompile(`(?is)<base\b[^>]*>`)
+	headCloseRe = regexp.MustCompile(`(?i)</head\s*>`)
+)
+
+// DiscoverFeeds returns pageUrl itself if it's a feed. Otherwise it returns the feeds the page links to with
+// <link rel="alternate">, falling back to the feeds found at common paths of its site.
+func DiscoverFeeds(ctx context.Context, pageUrl string) ([]DiscoveredFeed, error) {
+	body, contentType, base, err := get(ctx, pageUrl)
+	if err != nil {
+		return nil, err
+	}
+	feed, err := parseFeed(body, contentType)
+	if err == nil {
+		unescapeFeed(feed)
+		return []DiscoveredFeed{{URL: pageUrl, Title: feed.Channel.Title, Feed: feed}}, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if slices.Contains(feedMediaTypes, mediaType) || slices.Contains(xmlMediaTypes, mediaType) {
+		return nil, err
+	}
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("'%s' is neither a feed nor a web page (%s)", pageUrl, mediaType)
+	}
+	feeds := linkedFeeds(string(body), base)
+	if len(feeds) > 0 {
+		return feeds, nil
+	}
+	for _, path := range commonFeedPaths {
+		feedUrl := base.ResolveReference(&url.URL{Path: path}).String()
+		body, contentType, _, err := get(ctx, feedUrl)
+		if err != nil {
+			continue
+		}
+		if feed, err := parseFeed(body, contentType); err == nil {
+			unescapeFeed(feed)
+			feeds = append(feeds, DiscoveredFeed{URL: feedUrl, Title: feed.Channel.Title, Feed: feed})
+		}
+	}
+	return feeds, nil
+}
+
+// linkedFeeds returns the feeds advertised in the page's <head>, resolving their urls against base.
+func linkedFeeds(page string, base *url.URL) []DiscoveredFeed {
+	if loc := headCloseRe.FindStringIndex(page); loc != nil {
+		page = page[:loc[0]]
+	}
+	if tag := baseHrefRe.FindString(page); tag != "" {
+		if href, err := url.Parse(htmlAttrs(tag)["href"]); err == nil {
+			base = base.ResolveReference(href)
+		}
+	}
+	var feeds []DiscoveredFeed
+	for _, tag := range linkTagRe.FindAllString(page, -1) {
+		attrs := htmlAttrs(tag)
+		rels := strings.Fields(strings.ToLower(attrs["rel"]))
+		mediaType := strings.ToLower(strings.TrimSpace(attrs["type"]))
+		if !slices.Contains(rels, "alternate") || !slices.Contains(feedMediaTypes, mediaType) {
+			continue
+		}
+		href, err := url.Parse(strings.TrimSpace(attrs["href"]))
+		if err != nil || attrs["href"] == "" {
+			continue
+		}
+		feedUrl := base.ResolveReference(href).String()
+		if slices.ContainsFunc(feeds, func(f DiscoveredFeed) bool { return f.URL == feedUrl }) {
+			continue
+		}
+		feeds = append(feeds, DiscoveredFeed{URL: feedUrl, Title: attrs["title"]})
+	}
+	return feeds
+}
+
+// htmlAttrs returns the attributes of an html tag, keyed by their lowercased names.
+func htmlAttrs(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range htmlAttrRe.FindAllStringSubmatch(tag, -1) {
+		name := strings.ToLower(match[1])
+		if _, ok := attrs[name]; !ok {
+			attrs[name] = html.UnescapeString(match[2] + match[3] + match[4])
+		}
+	}
+	return attrs
+}
+
+// get fetches pageUrl, returning its body, content type and the url it was finally served from.
+func get(ctx context.Context, pageUrl string) ([]byte, string, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageUrl, nil)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create new request: %w", err)
+	}
+	req.Header.Set("User-Agent", "gator")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, "", nil, fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+	body, err := readBody(res.Body)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return body, res.Header.Get("Content-Type"), res.Request.URL, nil
+}
@@ -0,0 +1,80 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverFeeds(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Tom &amp;amp; Jerry</title></channel></rss>`)
+	})
+	mux.HandleFunc("/broken.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Broken</title></chan>`)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><link rel="alternate" type="application/atom+xml" title="Atom" href="/atom.xml"></head></html>`)
+	})
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"error": "not found"}`)
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		mux.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	t.Run("feed", func(t *testing.T) {
+		requests = 0
+		feeds, err := DiscoverFeeds(ctx, server.URL+"/feed.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(feeds) != 1 || feeds[0].Feed == nil || feeds[0].Title != "Tom & Jerry" {
+			t.Fatalf("DiscoverFeeds = %+v, want the parsed feed itself", feeds)
+		}
+		if requests != 1 {
+			t.Errorf("DiscoverFeeds made %d requests, want 1", requests)
+		}
+	})
+	t.Run("broken feed", func(t *testing.T) {
+		_, err := DiscoverFeeds(ctx, server.URL+"/broken.xml")
+		if err == nil || !strings.Contains(err.Error(), "unmarshal") {
+			t.Errorf("DiscoverFeeds returned error %v, want the parse error", err)
+		}
+	})
+	t.Run("page", func(t *testing.T) {
+		feeds, err := DiscoverFeeds(ctx, server.URL+"/page")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(feeds) != 1 || feeds[0].URL != server.URL+"/atom.xml" || feeds[0].Feed != nil {
+			t.Errorf("DiscoverFeeds = %+v, want the linked atom feed", feeds)
+		}
+	})
+	t.Run("json that isn't a feed", func(t *testing.T) {
+		if feeds, err := DiscoverFeeds(ctx, server.URL+"/api"); err == nil {
+			t.Errorf("DiscoverFeeds = %+v, want an error", feeds)
+		}
+	})
+	t.Run("neither", func(t *testing.T) {
+		_, err := DiscoverFeeds(ctx, server.URL+"/image.png")
+		if err == nil || !strings.Contains(err.Error(), "neither a feed nor a web page") {
+			t.Errorf("DiscoverFeeds returned error %v, want neither a feed nor a web page", err)
+		}
+	})
+}
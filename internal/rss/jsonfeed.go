@@ -0,0 +1,55 @@
+package rss
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Icon        string         `json:"icon"`
+	Favicon     string         `json:"favicon"`
+	Language    string         `json:"language"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+// toRSS maps the JSON feed into the common RSSFeed model.
+func (j *JSONFeed) toRSS() *RSSFeed {
+	feed := RSSFeed{}
+	feed.Channel.Title = j.Title
+	feed.Channel.Link = j.HomePageURL
+	feed.Channel.Description = j.Description
+	feed.Channel.Language = j.Language
+	feed.Channel.Image.URL = j.Icon
+	if feed.Channel.Image.URL == "" {
+		feed.Channel.Image.URL = j.Favicon
+	}
+	for _, entry := range j.Items {
+		item := RSSItem{
+			GUID:        entry.ID,
+			Title:       entry.Title,
+			Link:        entry.URL,
+			Description: entry.ContentHTML,
+			PubDate:     entry.DatePublished,
+			Updated:     entry.DateModified,
+		}
+		if item.Description == "" {
+			item.Description = entry.Summary
+		}
+		if item.Description == "" {
+			item.Description = entry.ContentText
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	return &feed
+}
@@ -0,0 +1,68 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseJSONFeed(t *testing.T) {
+	body := `{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "JSON Blog",
+	"home_page_url": "https://example.com/",
+	"feed_url": "https://example.com/feed.json",
+	"description": "A blog in JSON",
+	"favicon": "https://example.com/favicon.ico",
+	"language": "fr",
+	"items": [
+		{"id": "1", "url": "https://example.com/1", "title": "Html", "content_html": "<p>Html</p>", "content_text": "Text", "summary": "Summary", "date_published": "2024-08-01T10:00:00+02:00", "date_modified": "2024-08-03T10:00:00Z"},
+		{"id": "2", "url": "https://example.com/2", "title": "Summary", "content_text": "Text", "summary": "Summary", "date_modified": "2024-08-03T10:00:00Z"},
+		{"id": "3", "title": "Text", "content_text": "Text"}
+	]
+}`
+	feed, err := parseFeed([]byte(body), "application/feed+json")
+	if err != nil {
+		t.Fatalf("parseFeed returned error: %v", err)
+	}
+	channel := feed.Channel
+	assertEqual(t, "format", feed.Format, "JSON Feed 1.1")
+	assertEqual(t, "title", channel.Title, "JSON Blog")
+	assertEqual(t, "link", channel.Link, "https://example.com/")
+	assertEqual(t, "description", channel.Description, "A blog in JSON")
+	assertEqual(t, "language", channel.Language, "fr")
+	assertEqual(t, "icon", channel.Image.URL, "https://example.com/favicon.ico")
+	if len(channel.Items) != 3 {
+		t.Fatalf("got %d items, want 3", len(channel.Items))
+	}
+	fetchedAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		guid, link, description string
+		published               time.Time
+	}{
+		{"1", "https://example.com/1", "<p>Html</p>", time.Date(2024, 8, 1, 8, 0, 0, 0, time.UTC)},
+		{"2", "https://example.com/2", "Summary", time.Date(2024, 8, 3, 10, 0, 0, 0, time.UTC)},
+		{"3", "", "Text", fetchedAt},
+	}
+	for i, tt := range tests {
+		item := channel.Items[i]
+		assertEqual(t, "guid", item.GUID, tt.guid)
+		assertEqual(t, "link", item.Link, tt.link)
+		assertEqual(t, "description", item.Description, tt.description)
+		published, err := item.PublishedAt(fetchedAt)
+		if err != nil {
+			t.Fatalf("item %s: PublishedAt returned error: %v", tt.guid, err)
+		}
+		if !published.Equal(tt.published) {
+			t.Errorf("item %s: PublishedAt = %v, want %v", tt.guid, published, tt.published)
+		}
+	}
+}
+
+func TestParseJSONFeedIcon(t *testing.T) {
+	body := `{"version": "https://jsonfeed.org/version/1", "title": "T", "icon": "https://example.com/icon.png", "favicon": "https://example.com/favicon.ico"}`
+	feed, err := parseFeed([]byte(body), "application/json")
+	if err != nil {
+		t.Fatalf("parseFeed returned error: %v", err)
+	}
+	assertEqual(t, "icon", feed.Channel.Image.URL, "https://example.com/icon.png")
+}
@@ -0,0 +1,41 @@
+package rss
+
+// RDFFeed is an RSS 1.0 document, where items are siblings of the channel rather than children.
+type RDFFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		Language    string `xml:"http://purl.org/dc/elements/1.1/ language"`
+	} `xml:"channel"`
+	Image RSSImage  `xml:"image"`
+	Items []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+// toRSS maps the RDF feed into the common RSSFeed model.
+func (r *RDFFeed) toRSS() *RSSFeed {
+	feed := RSSFeed{}
+	feed.Channel.Title = r.Channel.Title
+	feed.Channel.Link = r.Channel.Link
+	feed.Channel.Description = r.Channel.Description
+	feed.Channel.Language = r.Channel.Language
+	feed.Channel.Image = r.Image
+	for _, entry := range r.Items {
+		feed.Channel.Items = append(feed.Channel.Items, RSSItem{
+			GUID:        entry.About,
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Description,
+			DCDate:      entry.Date,
+		})
+	}
+	return &feed
+}
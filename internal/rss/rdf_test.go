@@ -0,0 +1,66 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRDF(t *testing.T) {
+	body := `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns="http://purl.org/rss/1.0/">
+<channel rdf:about="https://example.com/">
+	<title>RDF Site</title>
+	<link>https://example.com/</link>
+	<description>An RSS 1.0 feed</description>
+	<dc:language>de</dc:language>
+	<items><rdf:Seq><rdf:li resource="https://example.com/1"/><rdf:li resource="https://example.com/2"/></rdf:Seq></items>
+</channel>
+<image rdf:about="https://example.com/logo.png"><url>https://example.com/logo.png</url></image>
+<item rdf:about="https://example.com/1">
+	<title>First</title>
+	<link>https://example.com/1</link>
+	<description>The first item</description>
+	<dc:date>2024-08-01T10:00:00+02:00</dc:date>
+</item>
+<item rdf:about="https://example.com/2">
+	<title>Second</title>
+	<link>https://example.com/2</link>
+</item>
+</rdf:RDF>`
+	feed, err := parseFeed([]byte(body), "application/rdf+xml")
+	if err != nil {
+		t.Fatalf("parseFeed returned error: %v", err)
+	}
+	channel := feed.Channel
+	assertEqual(t, "format", feed.Format, "RSS 1.0 (RDF)")
+	assertEqual(t, "title", channel.Title, "RDF Site")
+	assertEqual(t, "link", channel.Link, "https://example.com/")
+	assertEqual(t, "description", channel.Description, "An RSS 1.0 feed")
+	assertEqual(t, "language", channel.Language, "de")
+	assertEqual(t, "image", channel.Image.URL, "https://example.com/logo.png")
+	if len(channel.Items) != 2 {
+		t.Fatalf("got %d items, want 2", len(channel.Items))
+	}
+	fetchedAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		guid, title, description string
+		published                time.Time
+	}{
+		{"https://example.com/1", "First", "The first item", time.Date(2024, 8, 1, 8, 0, 0, 0, time.UTC)},
+		{"https://example.com/2", "Second", "", fetchedAt},
+	}
+	for i, tt := range tests {
+		item := channel.Items[i]
+		assertEqual(t, "guid", item.GUID, tt.guid)
+		assertEqual(t, "title", item.Title, tt.title)
+		assertEqual(t, "link", item.Link, tt.guid)
+		assertEqual(t, "description", item.Description, tt.description)
+		published, err := item.PublishedAt(fetchedAt)
+		if err != nil {
+			t.Fatalf("item %s: PublishedAt returned error: %v", tt.guid, err)
+		}
+		if !published.Equal(tt.published) {
+			t.Errorf("item %s: PublishedAt = %v, want %v", tt.guid, published, tt.published)
+		}
+	}
+}
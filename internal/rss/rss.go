@@ -1,57 +1,265 @@
 package rss
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"html"
 	"io"
+	"mime"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 )
 
 type RSSFeed struct {
+	// Format is the kind of document the feed was parsed from, such as "RSS 2.0" or "Atom".
+	Format  string `xml:"-"`
+	Version string `xml:"version,attr"`
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Items       []RSSItem `xml:"item"`
+		Title string `xml:"title"`
+		// AtomLinks must come before Link, otherwise an <atom:link> would overwrite the <link> to the site.
+		AtomLinks   []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+		Link        string     `xml:"link"`
+		Description string     `xml:"description"`
+		Language    string     `xml:"language"`
+		Image       RSSImage   `xml:"image"`
+		TTL         string     `xml:"ttl"`
+		SkipHours   []string   `xml:"skipHours>hour"`
+		SkipDays    []string   `xml:"skipDays>day"`
+		Items       []RSSItem  `xml:"item"`
 	} `xml:"channel"`
 }
 
+type RSSImage struct {
+	URL string `xml:"url"`
+}
+
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	GUID  string `xml:"guid"`
+	Title string `xml:"title"`
+	// AtomLinks must come before Link, for the same reason as the channel's.
+	AtomLinks   []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+	Link        string     `xml:"link"`
+	Description string     `xml:"description"`
+	PubDate     string     `xml:"pubDate"`
+	DCDate      string     `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Updated     string     `xml:"http://www.w3.org/2005/Atom updated"`
+}
+
+const (
+	// fetchTimeout bounds a whole request, from connecting to reading the body.
+	fetchTimeout = 30 * time.Second
+	// maxBodySize is the largest response body read, so a huge or endless response can't exhaust memory.
+	maxBodySize = 10 << 20
+)
+
+var client = &http.Client{Timeout: fetchTimeout}
+
+// ErrNotModified is returned by FetchFeed when the server answers a conditional request with 304 Not Modified.
+var ErrNotModified = errors.New("feed not modified")
+
+// Validators are the HTTP cache validators of a previous response, used to make conditional requests.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// FetchInfo describes the HTTP response a feed was fetched from.
+type FetchInfo struct {
+	Validators
+	StatusCode  int
+	ContentType string
+	Encoding    string
+	MaxAge      time.Duration
+	RetryAfter  time.Duration
 }
 
-func FetchFeed(ctx context.Context, feedUrl string) (*RSSFeed, error) {
+// FetchFeed fetches and parses the feed at feedUrl, returning what is known about the response.
+// If the feed hasn't changed since the given validators were issued, it returns ErrNotModified.
+func FetchFeed(ctx context.Context, feedUrl string, validators Validators) (*RSSFeed, FetchInfo, error) {
+	info := FetchInfo{Validators: validators}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedUrl, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new request: %w", err)
+		return nil, info, fmt.Errorf("failed to create new request: %w", err)
 	}
 	req.Header.Set("User-Agent", "gator")
-	client := &http.Client{}
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
 	res, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, info, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
+	info.StatusCode = res.StatusCode
+	info.ContentType = res.Header.Get("Content-Type")
+	info.MaxAge = parseMaxAge(res.Header.Get("Cache-Control"))
+	info.RetryAfter = parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	if res.StatusCode == http.StatusNotModified {
+		return nil, info, ErrNotModified
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, info, fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+	body, err := readBody(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, info, err
 	}
-	feed := RSSFeed{}
-	err = xml.Unmarshal(body, &feed)
+	info.Encoding = detectEncoding(body, info.ContentType)
+	feed, err := parseFeed(body, info.ContentType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal body as a RRS feed: %w", err)
+		return nil, info, err
 	}
+	unescapeFeed(feed)
+	info.Validators = Validators{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+	return feed, info, nil
+}
+
+// unescapeFeed decodes the html entities left in the feed's and its items' titles and descriptions.
+func unescapeFeed(feed *RSSFeed) {
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
 	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
 	for i, item := range feed.Channel.Items {
 		feed.Channel.Items[i].Title = html.UnescapeString(item.Title)
 		feed.Channel.Items[i].Description = html.UnescapeString(item.Description)
 	}
-	return &feed, nil
+}
+
+// readBody reads a response body, failing if it's larger than maxBodySize.
+func readBody(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if len(body) > maxBodySize {
+		return nil, fmt.Errorf("response body is larger than %d bytes", maxBodySize)
+	}
+	return body, nil
+}
+
+// ID returns the item's unique identifier, falling back to its link when the feed provides no guid.
+func (item RSSItem) ID() string {
+	if guid := strings.TrimSpace(item.GUID); guid != "" {
+		return guid
+	}
+	return strings.TrimSpace(item.Link)
+}
+
+// PublishedAt parses the item's publication date, falling back to its dc:date and updated dates.
+// Items without any date are considered published at fetchedAt.
+func (item RSSItem) PublishedAt(fetchedAt time.Time) (time.Time, error) {
+	var lastErr error
+	for _, value := range []string{item.PubDate, item.DCDate, item.Updated} {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		pubDate, err := ParseDate(value)
+		if err == nil {
+			return pubDate, nil
+		}
+		lastErr = err
+	}
+	if lastErr != nil {
+		return time.Time{}, lastErr
+	}
+	return fetchedAt, nil
+}
+
+// parseFeed detects the feed format from the content type or the document's root element and maps it into an RSSFeed.
+func parseFeed(body []byte, contentType string) (*RSSFeed, error) {
+	if isJSONFeed(body, contentType) {
+		jsonFeed := JSONFeed{}
+		if err := json.Unmarshal(body, &jsonFeed); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal body as a JSON feed: %w", err)
+		}
+		// any JSON object would unmarshal, so only the version tells a JSON Feed from other JSON documents
+		version, ok := strings.CutPrefix(jsonFeed.Version, "https://jsonfeed.org/version/")
+		if !ok {
+			return nil, fmt.Errorf("not a JSON feed, version is '%s'", jsonFeed.Version)
+		}
+		feed := jsonFeed.toRSS()
+		feed.Format = "JSON Feed " + version
+		return feed, nil
+	}
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect feed format: %w", err)
+	}
+	switch root {
+	case "rss":
+		feed := RSSFeed{}
+		if err = xml.Unmarshal(body, &feed); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal body as a RRS feed: %w", err)
+		}
+		feed.Format = strings.TrimSpace("RSS " + feed.Version)
+		return &feed, nil
+	case "feed":
+		atom := AtomFeed{}
+		if err = xml.Unmarshal(body, &atom); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal body as an Atom feed: %w", err)
+		}
+		feed := atom.toRSS()
+		feed.Format = "Atom"
+		return feed, nil
+	case "RDF":
+		rdf := RDFFeed{}
+		if err = xml.Unmarshal(body, &rdf); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal body as an RDF feed: %w", err)
+		}
+		feed := rdf.toRSS()
+		feed.Format = "RSS 1.0 (RDF)"
+		return feed, nil
+	default:
+		return nil, fmt.Errorf("unsupported feed format '<%s>'", root)
+	}
+}
+
+func isJSONFeed(body []byte, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/feed+json" || mediaType == "application/json") {
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("{"))
+}
+
+var xmlEncodingRe = regexp.MustCompile(`^\s*<\?xml[^>]*\sencoding\s*=\s*["']([^"']+)["']`)
+
+// detectEncoding returns the body's character encoding: the content type's charset, else the one
+// declared by the XML declaration, else utf-8.
+func detectEncoding(body []byte, contentType string) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		return strings.ToLower(params["charset"])
+	}
+	if match := xmlEncodingRe.FindSubmatch(body); match != nil {
+		return strings.ToLower(string(match[1]))
+	}
+	return "utf-8"
+}
+
+func rootElement(body []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", errors.New("no root element found")
+			}
+			return "", err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
 }
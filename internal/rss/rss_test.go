@@ -0,0 +1,96 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchFeedBodyLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>`)
+		fmt.Fprint(w, strings.Repeat("x", maxBodySize))
+		fmt.Fprint(w, `</title></channel></rss>`)
+	}))
+	defer server.Close()
+	_, _, err := FetchFeed(context.Background(), server.URL, Validators{})
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("FetchFeed of an oversized feed returned error %v, want the body to be too large", err)
+	}
+}
+
+func TestParseFeedFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+		wantErr     string
+	}{
+		{"rss 2.0", `<?xml version="1.0"?><rss version="2.0"><channel><title>T</title></channel></rss>`, "application/rss+xml", "RSS 2.0", ""},
+		{"rss 0.91", `<rss version="0.91"><channel><title>T</title></channel></rss>`, "text/xml", "RSS 0.91", ""},
+		{"rss without version", `<rss><channel><title>T</title></channel></rss>`, "", "RSS", ""},
+		{"atom", `<feed xmlns="http://www.w3.org/2005/Atom"><title>T</title></feed>`, "application/xml", "Atom", ""},
+		{"atom served as rss", `<!-- comment --><feed xmlns="http://www.w3.org/2005/Atom"><title>T</title></feed>`, "application/rss+xml", "Atom", ""},
+		{"rdf", `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/"><channel><title>T</title></channel></rdf:RDF>`, "application/rdf+xml", "RSS 1.0 (RDF)", ""},
+		{"json feed", `{"version": "https://jsonfeed.org/version/1.1", "title": "T", "items": []}`, "application/feed+json", "JSON Feed 1.1", ""},
+		{"json feed served as json", `{"version": "https://jsonfeed.org/version/1", "title": "T"}`, "application/json", "JSON Feed 1", ""},
+		{"json feed served as text", ` {"version": "https://jsonfeed.org/version/1.1", "title": "T"}`, "text/plain", "JSON Feed 1.1", ""},
+		{"json without version", `{"error": "not found"}`, "application/json", "", "not a JSON feed, version is ''"},
+		{"json with another version", `{"version": "2.0", "title": "T"}`, "application/json", "", "not a JSON feed, version is '2.0'"},
+		{"json array", `[]`, "application/json", "", "failed to unmarshal body as a JSON feed"},
+		{"html", `<html><head><title>T</title></head></html>`, "text/html", "", "unsupported feed format '<html>'"},
+		{"empty", ``, "application/xml", "", "no root element found"},
+		{"broken", `<rss version="2.0"><channel></rss>`, "application/xml", "", "failed to unmarshal body as a RRS feed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := parseFeed([]byte(tt.body), tt.contentType)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseFeed returned error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFeed returned error: %v", err)
+			}
+			assertEqual(t, "format", feed.Format, tt.want)
+			assertEqual(t, "title", feed.Channel.Title, "T")
+		})
+	}
+}
+
+func TestParseRSSAtomLinks(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		body string
+	}{
+		{"atom:link first", `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel><title>T</title>
+<atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"/>
+<link>https://example.com/</link>
+<item><title>I</title><atom:link href="https://example.com/item/comments.xml" rel="replies"/><link>https://example.com/item</link></item>
+</channel></rss>`},
+		{"atom:link last", `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel><title>T</title>
+<link>https://example.com/</link>
+<atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"/>
+<item><title>I</title><link>https://example.com/item</link><atom:link href="https://example.com/item/comments.xml" rel="replies"/></item>
+</channel></rss>`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := parseFeed([]byte(tt.body), "application/rss+xml")
+			if err != nil {
+				t.Fatalf("parseFeed returned error: %v", err)
+			}
+			if len(feed.Channel.Items) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Channel.Items))
+			}
+			assertEqual(t, "channel link", feed.Channel.Link, "https://example.com/")
+			assertEqual(t, "item link", feed.Channel.Items[0].Link, "https://example.com/item")
+		})
+	}
+}
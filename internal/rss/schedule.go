@@ -0,0 +1,119 @@
+package rss
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRefreshInterval = time.Hour
+	MinRefreshInterval     = 15 * time.Minute
+	MaxRefreshInterval     = 24 * time.Hour
+	MaxBackoff             = 7 * 24 * time.Hour
+)
+
+// NextFetch computes when a feed should be fetched next. The interval adapts to how often the feed
+// posts, given the publication dates of its latest posts, and honours the feed's ttl, skipHours and
+// skipDays as well as the response's Cache-Control max-age and Retry-After. feed may be nil when the
+// response carried no body, such as a 304 Not Modified. The result is always in UTC.
+func NextFetch(fetchedAt time.Time, published []time.Time, feed *RSSFeed, info FetchInfo) time.Time {
+	interval := postingInterval(published)
+	if feed != nil {
+		if ttl, err := strconv.Atoi(strings.TrimSpace(feed.Channel.TTL)); err == nil && ttl > 0 {
+			interval = max(interval, time.Duration(ttl)*time.Minute)
+		}
+	}
+	interval = max(interval, info.MaxAge)
+	interval = min(max(interval, MinRefreshInterval), MaxRefreshInterval)
+	interval = max(interval, info.RetryAfter)
+
+	next := fetchedAt.Add(interval).UTC()
+	if feed == nil {
+		return next
+	}
+	skipHours, skipDays := skippedHours(feed.Channel.SkipHours), skippedDays(feed.Channel.SkipDays)
+	// skipHours and skipDays are expressed in GMT; bail out after a week in case every hour is skipped
+	for range 7 * 24 {
+		if !skipHours[next.Hour()] && !skipDays[next.Weekday()] {
+			break
+		}
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+	return next
+}
+
+// Backoff computes how long to wait before retrying a feed after its given number of consecutive
+// failures, doubling from MinRefreshInterval up to MaxBackoff and honouring any Retry-After.
+func Backoff(failures int, info FetchInfo) time.Duration {
+	backoff := MinRefreshInterval
+	for i := 1; i < failures && backoff < MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return max(min(backoff, MaxBackoff), info.RetryAfter)
+}
+
+// postingInterval estimates how often a feed posts from the publication dates of its latest posts,
+// returning half the average gap between them so new posts are picked up reasonably soon.
+func postingInterval(published []time.Time) time.Duration {
+	if len(published) < 2 {
+		return DefaultRefreshInterval
+	}
+	dates := slices.Clone(published)
+	slices.SortFunc(dates, func(a, b time.Time) int { return b.Compare(a) })
+	averageGap := dates[0].Sub(dates[len(dates)-1]) / time.Duration(len(dates)-1)
+	return averageGap / 2
+}
+
+func skippedHours(hours []string) map[int]bool {
+	skipped := make(map[int]bool)
+	for _, hour := range hours {
+		if h, err := strconv.Atoi(strings.TrimSpace(hour)); err == nil && h >= 0 && h < 24 {
+			skipped[h] = true
+		}
+	}
+	return skipped
+}
+
+func skippedDays(days []string) map[time.Weekday]bool {
+	skipped := make(map[time.Weekday]bool)
+	for _, day := range days {
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			if strings.EqualFold(strings.TrimSpace(day), weekday.String()) {
+				skipped[weekday] = true
+			}
+		}
+	}
+	return skipped
+}
+
+// parseMaxAge returns the max-age directive of a Cache-Control header.
+func parseMaxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+// parseRetryAfter returns the delay of a Retry-After header, given either in seconds or as an HTTP date.
+func parseRetryAfter(retryAfter string, now time.Time) time.Duration {
+	retryAfter = strings.TrimSpace(retryAfter)
+	if retryAfter == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
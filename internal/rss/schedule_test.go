@@ -0,0 +1,114 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextFetch(t *testing.T) {
+	// fetchedAt is given in a zone other than UTC, to check that schedules don't depend on the caller's zone
+	india := time.FixedZone("IST", 5*60*60+30*60)
+	pacific := time.FixedZone("PST", -8*60*60)
+	feed := func(ttl string, skipHours, skipDays []string) *RSSFeed {
+		feed := &RSSFeed{}
+		feed.Channel.TTL = ttl
+		feed.Channel.SkipHours = skipHours
+		feed.Channel.SkipDays = skipDays
+		return feed
+	}
+	tests := []struct {
+		name      string
+		fetchedAt time.Time
+		published []time.Time
+		feed      *RSSFeed
+		info      FetchInfo
+		want      time.Time
+	}{
+		{
+			name:      "not modified",
+			fetchedAt: time.Date(2024, 8, 1, 10, 0, 0, 0, india),
+			want:      time.Date(2024, 8, 1, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "default interval",
+			fetchedAt: time.Date(2024, 8, 1, 10, 0, 0, 0, india),
+			feed:      feed("", nil, nil),
+			want:      time.Date(2024, 8, 1, 5, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "posting interval",
+			fetchedAt: time.Date(2024, 8, 1, 10, 0, 0, 0, india),
+			published: []time.Time{time.Date(2024, 7, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 30, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 29, 0, 0, 0, 0, time.UTC)},
+			feed:      feed("", nil, nil),
+			want:      time.Date(2024, 8, 1, 16, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "ttl",
+			fetchedAt: time.Date(2024, 8, 1, 10, 0, 0, 0, india),
+			feed:      feed("180", nil, nil),
+			want:      time.Date(2024, 8, 1, 7, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "max-age is capped",
+			fetchedAt: time.Date(2024, 8, 1, 10, 0, 0, 0, india),
+			feed:      feed("", nil, nil),
+			info:      FetchInfo{MaxAge: 48 * time.Hour},
+			want:      time.Date(2024, 8, 2, 4, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "retry-after is not capped",
+			fetchedAt: time.Date(2024, 8, 1, 10, 0, 0, 0, india),
+			info:      FetchInfo{RetryAfter: 48 * time.Hour},
+			want:      time.Date(2024, 8, 3, 4, 30, 0, 0, time.UTC),
+		},
+		{
+			// 05:30 and 06:00 UTC are skipped, even though they're 11:00 and 11:30 where the feed was fetched
+			name:      "skip hours in GMT",
+			fetchedAt: time.Date(2024, 8, 1, 10, 0, 0, 0, india),
+			feed:      feed("", []string{"5", " 6 ", "25", "x"}, nil),
+			want:      time.Date(2024, 8, 1, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			// 00:30 UTC is on Thursday, though still Wednesday where the feed was fetched
+			name:      "skip days in GMT",
+			fetchedAt: time.Date(2024, 7, 31, 15, 30, 0, 0, pacific),
+			feed:      feed("", nil, []string{"thursday"}),
+			want:      time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "every hour skipped",
+			fetchedAt: time.Date(2024, 8, 1, 10, 0, 0, 0, india),
+			feed:      feed("", nil, []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}),
+			want:      time.Date(2024, 8, 8, 5, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextFetch(tt.fetchedAt, tt.published, tt.feed, tt.info)
+			if !got.Equal(tt.want) {
+				t.Errorf("NextFetch = %v, want %v", got, tt.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("NextFetch returned a time in %v, want UTC", got.Location())
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for _, tt := range []struct {
+		failures int
+		info     FetchInfo
+		want     time.Duration
+	}{
+		{1, FetchInfo{}, MinRefreshInterval},
+		{2, FetchInfo{}, 2 * MinRefreshInterval},
+		{4, FetchInfo{}, 8 * MinRefreshInterval},
+		{100, FetchInfo{}, MaxBackoff},
+		{1, FetchInfo{RetryAfter: time.Hour}, time.Hour},
+	} {
+		if got := Backoff(tt.failures, tt.info); got != tt.want {
+			t.Errorf("Backoff(%d, %+v) = %v, want %v", tt.failures, tt.info, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+-- name: CreateBookmark :one
+INSERT INTO bookmarks (user_id, post_id, created_at, feed_name, title, url, description, published_at)
+SELECT ff.user_id, p.id, NOW(), f.name, p.title, p.url, p.description, p.published_at
+FROM posts AS p
+INNER JOIN feeds AS f ON p.feed_id = f.id
+INNER JOIN feed_follows AS ff ON f.id = ff.feed_id
+WHERE ff.user_id = $1 AND p.id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE SET created_at = bookmarks.created_at
+RETURNING *;
+
+-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetBookmarksForUser :many
+SELECT * FROM bookmarks
+WHERE user_id = $1
+ORDER BY created_at DESC;
\ No newline at end of file
@@ -1,7 +1,7 @@
 -- name: CreateFeedFollow :one
 WITH inserted AS (
-  INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
-  VALUES ($1, $2, $3, $4, $5)
+  INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, folder)
+  VALUES ($1, $2, $3, $4, $5, $6)
   RETURNING *
 )
 SELECT 
@@ -15,7 +15,8 @@ JOIN users AS u ON inserted.user_id = u.id
 JOIN feeds AS f ON inserted.feed_id = f.id;
 
 -- name: GetFeedFollowsForUser :many
-SELECT ff.id, ff.created_at, ff.updated_at, f.name AS feed_name
+SELECT ff.id, ff.created_at, ff.updated_at, ff.feed_id, ff.folder, f.name AS feed_name, f.url AS feed_url,
+  f.title AS feed_title, f.site_url AS feed_site_url
 FROM feed_follows AS ff
 JOIN feeds AS f ON ff.feed_id = f.id
 WHERE ff.user_id = $1;
@@ -1,7 +1,7 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, title, description, site_url, language, icon_url)
 VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 )
 RETURNING *;
 
@@ -10,20 +10,41 @@ SELECT * FROM feeds
 WHERE url = $1;
 
 -- name: GetUserFeeds :many
-SELECT f.id, f.created_at, f.updated_at, f.name, f.url, u.name AS user_name
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.consecutive_failures, f.last_error, f.disabled_at,
+  f.title, f.description, f.site_url, f.language, u.name AS user_name
 FROM feeds AS f
 JOIN users AS u ON f.user_id = u.id;
 
--- name: DeleteFeed :exec
+-- name: DeleteFeed :execrows
 DELETE FROM feeds
 WHERE url=$1;
 
 -- name: MarkFeedFetched :exec
 UPDATE feeds
-SET last_fetched_at = NOW(), updated_at = NOW()
+SET last_fetched_at = NOW(), updated_at = NOW(), etag = $2, last_modified = $3, next_fetch_at = $4,
+    last_status = $5, consecutive_failures = 0, last_error = NULL
 WHERE id = $1;
 
--- name: GetNextFeedToFetch :one
-SELECT *
-FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST;
\ No newline at end of file
+-- name: MarkFeedFailed :exec
+UPDATE feeds
+SET updated_at = NOW(), consecutive_failures = consecutive_failures + 1, last_error = $2, last_status = $3,
+    next_fetch_at = $4, disabled_at = $5
+WHERE id = $1;
+
+-- name: EnableFeed :execrows
+UPDATE feeds
+SET updated_at = NOW(), consecutive_failures = 0, last_error = NULL, disabled_at = NULL, next_fetch_at = NULL
+WHERE url = $1;
+
+-- name: ClaimNextFeedToFetch :one
+UPDATE feeds
+SET last_fetched_at = NOW(), next_fetch_at = NOW() + INTERVAL '10 minutes'
+WHERE id = (
+    SELECT f.id
+    FROM feeds AS f
+    WHERE f.disabled_at IS NULL AND (f.next_fetch_at IS NULL OR f.next_fetch_at <= NOW())
+    ORDER BY f.next_fetch_at ASC NULLS FIRST
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
\ No newline at end of file
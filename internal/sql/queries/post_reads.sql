@@ -0,0 +1,28 @@
+-- name: MarkPostRead :one
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT ff.user_id, p.id, NOW()
+FROM posts AS p
+INNER JOIN feed_follows AS ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1 AND p.id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE SET read_at = post_reads.read_at
+RETURNING *;
+
+-- name: MarkPostUnread :exec
+DELETE FROM post_reads
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT ff.user_id, p.id, NOW()
+FROM posts AS p
+INNER JOIN feed_follows AS ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkFeedPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT ff.user_id, p.id, NOW()
+FROM posts AS p
+INNER JOIN feed_follows AS ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1 AND ff.feed_id = $2
+ON CONFLICT (user_id, post_id) DO NOTHING;
\ No newline at end of file
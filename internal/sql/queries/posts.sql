@@ -1,5 +1,5 @@
--- name: CreatePost :one
-INSERT INTO posts (id, feed_id, created_at, updated_at, title, url, description, published_at)
+-- name: UpsertPost :one
+INSERT INTO posts (id, feed_id, created_at, updated_at, title, url, description, published_at, guid)
 VALUES (
     gen_random_uuid (),
     $1,
@@ -8,15 +8,59 @@ VALUES (
     $2,
     $3,
     $4,
-    $5
-) RETURNING *;
+    $5,
+    $6
+)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title, url = EXCLUDED.url, description = EXCLUDED.description, updated_at = NOW()
+WHERE posts.title <> EXCLUDED.title OR posts.url <> EXCLUDED.url OR posts.description <> EXCLUDED.description
+RETURNING *;
+
+-- name: RekeyLegacyPost :execrows
+UPDATE posts
+SET guid = sqlc.arg(guid)
+WHERE feed_id = sqlc.arg(feed_id) AND guid = sqlc.arg(url) AND url = sqlc.arg(url)
+    AND NOT EXISTS (SELECT 1 FROM posts AS p WHERE p.feed_id = sqlc.arg(feed_id) AND p.guid = sqlc.arg(guid));
 
 -- name: GetPostsFromUser :many
-WITH userposts AS (
-    SELECT ff.feed_id FROM feed_follows as ff WHERE ff.user_id = $1
-)
-SELECT *
+SELECT p.*, pr.read_at
 FROM posts AS p
-INNER JOIN userposts ON p.feed_id = userposts.feed_id
+INNER JOIN feed_follows AS ff ON p.feed_id = ff.feed_id
+LEFT JOIN post_reads AS pr ON p.id = pr.post_id AND pr.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg(user_id)
+    AND (sqlc.arg(include_read)::boolean OR pr.post_id IS NULL)
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR p.feed_id = sqlc.narg(feed_id))
+    AND (sqlc.narg(since)::timestamp IS NULL OR p.published_at >= sqlc.narg(since))
+    AND (sqlc.narg(until)::timestamp IS NULL OR p.published_at < sqlc.narg(until))
+    AND (sqlc.narg(after_published_at)::timestamp IS NULL
+        OR (sqlc.arg(oldest_first)::boolean AND (p.published_at, p.id) > (sqlc.narg(after_published_at), sqlc.narg(after_id)::uuid))
+        OR (NOT sqlc.arg(oldest_first) AND (p.published_at, p.id) < (sqlc.narg(after_published_at), sqlc.narg(after_id))))
+ORDER BY
+    CASE WHEN sqlc.arg(oldest_first) THEN p.published_at END ASC,
+    CASE WHEN sqlc.arg(oldest_first) THEN p.id END ASC,
+    p.published_at DESC, p.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = $1;
+
+-- name: GetRecentPublishedDates :many
+SELECT published_at
+FROM posts
+WHERE feed_id = $1
 ORDER BY published_at DESC
-LIMIT $2 OFFSET $3;
\ No newline at end of file
+LIMIT 10;
+
+-- name: SearchPosts :many
+SELECT p.id, p.title, p.url, p.published_at, f.name AS feed_name,
+    ts_headline('english', p.title, q, 'HighlightAll=true, StartSel=**, StopSel=**') AS title_snippet,
+    ts_headline('english', p.description, q, 'MaxFragments=2, MaxWords=20, MinWords=8, StartSel=**, StopSel=**') AS snippet,
+    ts_rank(p.search_vector, q) AS rank
+FROM posts AS p
+INNER JOIN feeds AS f ON p.feed_id = f.id
+INNER JOIN feed_follows AS ff ON f.id = ff.feed_id
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg(query)) AS q
+WHERE ff.user_id = sqlc.arg(user_id) AND p.search_vector @@ q
+ORDER BY rank DESC, p.published_at DESC
+LIMIT sqlc.arg('limit');
\ No newline at end of file
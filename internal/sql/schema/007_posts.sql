@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN guid TEXT;
+UPDATE posts SET guid = url;
+DELETE FROM posts AS p USING posts AS dup
+WHERE p.feed_id = dup.feed_id AND p.guid = dup.guid AND (p.created_at, p.id) > (dup.created_at, dup.id);
+ALTER TABLE posts ALTER COLUMN guid SET NOT NULL;
+ALTER TABLE posts ADD CONSTRAINT posts_feed_id_guid_key UNIQUE (feed_id, guid);
+
+-- +goose Down
+ALTER TABLE posts DROP CONSTRAINT posts_feed_id_guid_key;
+ALTER TABLE posts DROP COLUMN guid;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feed_follows ADD COLUMN folder TEXT;
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN folder;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- +goose Up
+-- next_fetch_at was written in the aggregator's time zone but compared with NOW() in the session's, so reschedule every feed
+ALTER TABLE feeds ALTER COLUMN last_fetched_at TYPE TIMESTAMPTZ;
+ALTER TABLE feeds ALTER COLUMN next_fetch_at TYPE TIMESTAMPTZ;
+ALTER TABLE feeds ALTER COLUMN disabled_at TYPE TIMESTAMPTZ;
+UPDATE feeds SET next_fetch_at = NULL WHERE disabled_at IS NULL;
+
+-- +goose Down
+ALTER TABLE feeds ALTER COLUMN disabled_at TYPE TIMESTAMP;
+ALTER TABLE feeds ALTER COLUMN next_fetch_at TYPE TIMESTAMP;
+ALTER TABLE feeds ALTER COLUMN last_fetched_at TYPE TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,8 @@
+package schema
+
+import "embed"
+
+// FS holds the goose migrations that build the database schema.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,25 @@
+-- name: CreateFeedFollow :one
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id, folder)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING
+  id,
+  created_at,
+  updated_at,
+  (SELECT f.name FROM feeds AS f WHERE f.id = feed_id) AS feed_name,
+  (SELECT u.name FROM users AS u WHERE u.id = user_id) AS user_name;
+
+-- name: GetFeedFollowsForUser :many
+SELECT ff.id, ff.created_at, ff.updated_at, ff.feed_id, ff.folder, f.name AS feed_name, f.url AS feed_url,
+  f.title AS feed_title, f.site_url AS feed_site_url
+FROM feed_follows AS ff
+JOIN feeds AS f ON ff.feed_id = f.id
+WHERE ff.user_id = ?;
+
+-- name: DeleteFeedFollow :one
+DELETE FROM feed_follows
+WHERE user_id = sqlc.arg(user_id) AND feed_id IN (
+  SELECT f.id
+  FROM feeds AS f
+  WHERE f.user_id = sqlc.arg(user_id) AND f.url = sqlc.arg(url)
+)
+RETURNING feed_id, user_id;
\ No newline at end of file
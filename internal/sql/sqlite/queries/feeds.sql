@@ -0,0 +1,49 @@
+-- name: CreateFeed :one
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, title, description, site_url, language, icon_url)
+VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetFeed :one
+SELECT * FROM feeds
+WHERE url = ?;
+
+-- name: GetUserFeeds :many
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.consecutive_failures, f.last_error, f.disabled_at,
+  f.title, f.description, f.site_url, f.language, u.name AS user_name
+FROM feeds AS f
+JOIN users AS u ON f.user_id = u.id;
+
+-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE url=?;
+
+-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = sqlc.arg(now), updated_at = sqlc.arg(now), etag = sqlc.arg(etag), last_modified = sqlc.arg(last_modified),
+    next_fetch_at = sqlc.arg(next_fetch_at), last_status = sqlc.arg(last_status), consecutive_failures = 0, last_error = NULL
+WHERE id = sqlc.arg(id);
+
+-- name: MarkFeedFailed :exec
+UPDATE feeds
+SET updated_at = ?, consecutive_failures = consecutive_failures + 1, last_error = ?, last_status = ?,
+    next_fetch_at = ?, disabled_at = ?
+WHERE id = ?;
+
+-- name: EnableFeed :execrows
+UPDATE feeds
+SET updated_at = ?, consecutive_failures = 0, last_error = NULL, disabled_at = NULL, next_fetch_at = NULL
+WHERE url = ?;
+
+-- name: ClaimNextFeedToFetch :one
+UPDATE feeds
+SET last_fetched_at = sqlc.arg(now), next_fetch_at = sqlc.arg(lease_until)
+WHERE id = (
+    SELECT f.id
+    FROM feeds AS f
+    WHERE f.disabled_at IS NULL AND (f.next_fetch_at IS NULL OR f.next_fetch_at <= sqlc.arg(now))
+    ORDER BY f.next_fetch_at ASC
+    LIMIT 1
+)
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,58 @@
+-- name: UpsertPost :one
+INSERT INTO posts (id, feed_id, created_at, updated_at, title, url, description, published_at, guid)
+VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = excluded.title, url = excluded.url, description = excluded.description, updated_at = excluded.updated_at
+WHERE posts.title <> excluded.title OR posts.url <> excluded.url OR posts.description <> excluded.description
+RETURNING *;
+
+-- name: RekeyLegacyPost :execrows
+UPDATE posts
+SET guid = sqlc.arg(guid)
+WHERE feed_id = sqlc.arg(feed_id) AND guid = sqlc.arg(url) AND url = sqlc.arg(url)
+    AND NOT EXISTS (SELECT 1 FROM posts AS p WHERE p.feed_id = sqlc.arg(feed_id) AND p.guid = sqlc.arg(guid));
+
+-- name: GetPostsFromUser :many
+SELECT p.*, pr.read_at
+FROM posts AS p
+INNER JOIN feed_follows AS ff ON p.feed_id = ff.feed_id
+LEFT JOIN post_reads AS pr ON p.id = pr.post_id AND pr.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg(user_id)
+    AND (CAST(sqlc.arg(include_read) AS BOOLEAN) OR pr.post_id IS NULL)
+    AND (sqlc.narg(feed_id) IS NULL OR p.feed_id = sqlc.narg(feed_id))
+    AND (sqlc.narg(since) IS NULL OR p.published_at >= sqlc.narg(since))
+    AND (sqlc.narg(until) IS NULL OR p.published_at < sqlc.narg(until))
+    AND (sqlc.narg(after_published_at) IS NULL
+        OR (CAST(sqlc.arg(oldest_first) AS BOOLEAN) AND (p.published_at, p.id) > (sqlc.narg(after_published_at), sqlc.narg(after_id)))
+        OR (NOT sqlc.arg(oldest_first) AND (p.published_at, p.id) < (sqlc.narg(after_published_at), sqlc.narg(after_id))))
+ORDER BY
+    CASE WHEN sqlc.arg(oldest_first) THEN p.published_at END ASC,
+    CASE WHEN sqlc.arg(oldest_first) THEN p.id END ASC,
+    p.published_at DESC, p.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = ?;
+
+-- name: GetRecentPublishedDates :many
+SELECT published_at
+FROM posts
+WHERE feed_id = ?
+ORDER BY published_at DESC
+LIMIT 10;
+
+-- name: SearchPosts :many
+SELECT p.id, p.title, p.url, p.published_at, f.name AS feed_name,
+    CAST(highlight(posts_fts, 0, '**', '**') AS TEXT) AS title_snippet,
+    CAST(snippet(posts_fts, 1, '**', '**', '...', 20) AS TEXT) AS snippet,
+    CAST(bm25(posts_fts, 10.0, 1.0) AS REAL) AS rank
+FROM posts_fts
+INNER JOIN posts AS p ON posts_fts.rowid = p.rowid
+INNER JOIN feeds AS f ON p.feed_id = f.id
+INNER JOIN feed_follows AS ff ON f.id = ff.feed_id
+WHERE posts_fts MATCH sqlc.arg(query) AND ff.user_id = sqlc.arg(user_id)
+ORDER BY rank, p.published_at DESC
+LIMIT sqlc.arg('limit');
\ No newline at end of file
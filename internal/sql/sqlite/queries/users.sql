@@ -0,0 +1,16 @@
+-- name: CreateUser :one
+INSERT INTO users (id, created_at, updated_at, name)
+VALUES (
+    ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE name = ?;
+
+-- name: GetUsers :many
+SELECT * FROM users;
+
+-- name: DeleteUsers :exec
+DELETE FROM users;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN last_fetched_at DATETIME;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN last_fetched_at;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN guid TEXT NOT NULL DEFAULT '';
+UPDATE posts SET guid = url;
+DELETE FROM posts
+WHERE EXISTS (
+    SELECT 1 FROM posts AS dup
+    WHERE dup.feed_id = posts.feed_id AND dup.guid = posts.guid
+    AND (dup.created_at < posts.created_at OR (dup.created_at = posts.created_at AND dup.id < posts.id))
+);
+CREATE UNIQUE INDEX posts_feed_id_guid_key ON posts (feed_id, guid);
+
+-- +goose Down
+DROP INDEX posts_feed_id_guid_key;
+ALTER TABLE posts DROP COLUMN guid;
\ No newline at end of file
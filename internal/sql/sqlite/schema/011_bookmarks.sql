@@ -0,0 +1,17 @@
+-- +goose Up
+-- post_id has no foreign key on purpose: bookmarks keep a copy of the post so they outlive its feed.
+CREATE TABLE bookmarks(
+    user_id TEXT NOT NULL,
+    post_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    feed_name TEXT NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT NOT NULL,
+    published_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, post_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE bookmarks;
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- +goose Up
+-- The index follows posts by rowid, which only changes on VACUUM; rebuild it afterwards with
+-- INSERT INTO posts_fts (posts_fts) VALUES ('rebuild');
+CREATE VIRTUAL TABLE posts_fts USING fts5(title, description, content='posts', tokenize='porter unicode61');
+INSERT INTO posts_fts (posts_fts) VALUES ('rebuild');
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts (rowid, title, description) VALUES (new.rowid, new.title, new.description);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts BEGIN
+    INSERT INTO posts_fts (posts_fts, rowid, title, description) VALUES ('delete', old.rowid, old.title, old.description);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER posts_fts_update AFTER UPDATE ON posts BEGIN
+    INSERT INTO posts_fts (posts_fts, rowid, title, description) VALUES ('delete', old.rowid, old.title, old.description);
+    INSERT INTO posts_fts (rowid, title, description) VALUES (new.rowid, new.title, new.description);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER posts_fts_update;
+DROP TRIGGER posts_fts_delete;
+DROP TRIGGER posts_fts_insert;
+DROP TABLE posts_fts;
\ No newline at end of file
@@ -0,0 +1,678 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charlesaraya/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+type memoryData struct {
+	users     map[uuid.UUID]database.User
+	feeds     map[uuid.UUID]database.Feed
+	follows   map[uuid.UUID]database.FeedFollow
+	posts     map[uuid.UUID]database.Post
+	reads     map[userPostKey]database.PostRead
+	bookmarks map[userPostKey]database.Bookmark
+}
+
+type userPostKey struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		users:     maps.Clone(d.users),
+		feeds:     maps.Clone(d.feeds),
+		follows:   maps.Clone(d.follows),
+		posts:     maps.Clone(d.posts),
+		reads:     maps.Clone(d.reads),
+		bookmarks: maps.Clone(d.bookmarks),
+	}
+}
+
+// memoryStore keeps everything in maps, enforcing the same unique constraints and cascading
+// deletes as the SQL schema. It's meant for tests and for trying gator out without a database.
+type memoryStore struct {
+	mu   *sync.Mutex
+	data *memoryData
+	// inTx is set on the Store handed to InTx, which already holds the lock
+	inTx bool
+}
+
+func NewMemory() Store {
+	return &memoryStore{
+		mu: &sync.Mutex{},
+		data: &memoryData{
+			users:     make(map[uuid.UUID]database.User),
+			feeds:     make(map[uuid.UUID]database.Feed),
+			follows:   make(map[uuid.UUID]database.FeedFollow),
+			posts:     make(map[uuid.UUID]database.Post),
+			reads:     make(map[userPostKey]database.PostRead),
+			bookmarks: make(map[userPostKey]database.Bookmark),
+		},
+	}
+}
+
+func (s *memoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// InTx runs fn against a copy of the data, which replaces the original only if fn succeeds.
+func (s *memoryStore) InTx(ctx context.Context, fn func(Store) error) error {
+	defer s.lock()()
+	tx := &memoryStore{mu: s.mu, data: s.data.clone(), inTx: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	*s.data = *tx.data
+	return nil
+}
+
+func errUnique(constraint string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint \"%s\"", constraint)
+}
+
+func errForeignKey(constraint string) error {
+	return fmt.Errorf("insert violates foreign key constraint \"%s\"", constraint)
+}
+
+func (s *memoryStore) userByName(name string) (database.User, bool) {
+	for _, user := range s.data.users {
+		if user.Name == name {
+			return user, true
+		}
+	}
+	return database.User{}, false
+}
+
+func (s *memoryStore) feedByUrl(url string) (database.Feed, bool) {
+	for _, feed := range s.data.feeds {
+		if feed.Url == url {
+			return feed, true
+		}
+	}
+	return database.Feed{}, false
+}
+
+// deleteFeeds removes the matching feeds along with their follows, posts and the posts' reads.
+func (s *memoryStore) deleteFeeds(match func(database.Feed) bool) {
+	for id, feed := range s.data.feeds {
+		if !match(feed) {
+			continue
+		}
+		delete(s.data.feeds, id)
+		for followID, follow := range s.data.follows {
+			if follow.FeedID == id {
+				delete(s.data.follows, followID)
+			}
+		}
+		for postID, post := range s.data.posts {
+			if post.FeedID == id {
+				delete(s.data.posts, postID)
+			}
+		}
+		for key := range s.data.reads {
+			if _, ok := s.data.posts[key.PostID]; !ok {
+				delete(s.data.reads, key)
+			}
+		}
+	}
+}
+
+func (s *memoryStore) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	defer s.lock()()
+	if _, ok := s.userByName(arg.Name); ok {
+		return database.User{}, errUnique("users_name_key")
+	}
+	if _, ok := s.data.users[arg.ID]; ok {
+		return database.User{}, errUnique("users_pkey")
+	}
+	user := database.User{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+	}
+	s.data.users[user.ID] = user
+	return user, nil
+}
+
+func (s *memoryStore) GetUser(ctx context.Context, name string) (database.User, error) {
+	defer s.lock()()
+	user, ok := s.userByName(name)
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (s *memoryStore) GetUsers(ctx context.Context) ([]database.User, error) {
+	defer s.lock()()
+	users := slices.Collect(maps.Values(s.data.users))
+	slices.SortFunc(users, func(a, b database.User) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return users, nil
+}
+
+func (s *memoryStore) DeleteUsers(ctx context.Context) error {
+	defer s.lock()()
+	clear(s.data.users)
+	clear(s.data.follows)
+	clear(s.data.reads)
+	clear(s.data.bookmarks)
+	s.deleteFeeds(func(database.Feed) bool { return true })
+	return nil
+}
+
+func (s *memoryStore) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	defer s.lock()()
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return database.Feed{}, errForeignKey("feeds_user_id_fkey")
+	}
+	if _, ok := s.feedByUrl(arg.Url); ok {
+		return database.Feed{}, errUnique("feeds_url_key")
+	}
+	if _, ok := s.data.feeds[arg.ID]; ok {
+		return database.Feed{}, errUnique("feeds_pkey")
+	}
+	feed := database.Feed{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Name:        arg.Name,
+		Url:         arg.Url,
+		Title:       arg.Title,
+		Description: arg.Description,
+		SiteUrl:     arg.SiteUrl,
+		Language:    arg.Language,
+		IconUrl:     arg.IconUrl,
+	}
+	s.data.feeds[feed.ID] = feed
+	return feed, nil
+}
+
+func (s *memoryStore) GetFeed(ctx context.Context, url string) (database.Feed, error) {
+	defer s.lock()()
+	feed, ok := s.feedByUrl(url)
+	if !ok {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	return feed, nil
+}
+
+func (s *memoryStore) GetUserFeeds(ctx context.Context) ([]database.GetUserFeedsRow, error) {
+	defer s.lock()()
+	var items []database.GetUserFeedsRow
+	for _, feed := range s.data.feeds {
+		user, ok := s.data.users[feed.UserID]
+		if !ok {
+			continue
+		}
+		items = append(items, database.GetUserFeedsRow{
+			ID:                  feed.ID,
+			CreatedAt:           feed.CreatedAt,
+			UpdatedAt:           feed.UpdatedAt,
+			Name:                feed.Name,
+			Url:                 feed.Url,
+			ConsecutiveFailures: feed.ConsecutiveFailures,
+			LastError:           feed.LastError,
+			DisabledAt:          feed.DisabledAt,
+			Title:               feed.Title,
+			Description:         feed.Description,
+			SiteUrl:             feed.SiteUrl,
+			Language:            feed.Language,
+			UserName:            user.Name,
+		})
+	}
+	slices.SortFunc(items, func(a, b database.GetUserFeedsRow) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return items, nil
+}
+
+func (s *memoryStore) DeleteFeed(ctx context.Context, url string) (int64, error) {
+	defer s.lock()()
+	if _, ok := s.feedByUrl(url); !ok {
+		return 0, nil
+	}
+	s.deleteFeeds(func(feed database.Feed) bool { return feed.Url == url })
+	return 1, nil
+}
+
+func (s *memoryStore) EnableFeed(ctx context.Context, url string) (int64, error) {
+	defer s.lock()()
+	feed, ok := s.feedByUrl(url)
+	if !ok {
+		return 0, nil
+	}
+	feed.UpdatedAt = time.Now()
+	feed.ConsecutiveFailures = 0
+	feed.LastError = sql.NullString{}
+	feed.DisabledAt = sql.NullTime{}
+	feed.NextFetchAt = sql.NullTime{}
+	s.data.feeds[feed.ID] = feed
+	return 1, nil
+}
+
+func (s *memoryStore) ClaimNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	defer s.lock()()
+	now := time.Now()
+	var next *database.Feed
+	for _, feed := range s.data.feeds {
+		if feed.DisabledAt.Valid || (feed.NextFetchAt.Valid && feed.NextFetchAt.Time.After(now)) {
+			continue
+		}
+		if next == nil || !feed.NextFetchAt.Valid || (next.NextFetchAt.Valid && feed.NextFetchAt.Time.Before(next.NextFetchAt.Time)) {
+			next = &feed
+		}
+	}
+	if next == nil {
+		return database.Feed{}, sql.ErrNoRows
+	}
+	next.LastFetchedAt = sql.NullTime{Time: now, Valid: true}
+	next.NextFetchAt = sql.NullTime{Time: now.Add(claimLease), Valid: true}
+	s.data.feeds[next.ID] = *next
+	return *next, nil
+}
+
+func (s *memoryStore) MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error {
+	defer s.lock()()
+	feed, ok := s.data.feeds[arg.ID]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	feed.LastFetchedAt = sql.NullTime{Time: now, Valid: true}
+	feed.UpdatedAt = now
+	feed.Etag = arg.Etag
+	feed.LastModified = arg.LastModified
+	feed.NextFetchAt = arg.NextFetchAt
+	feed.LastStatus = arg.LastStatus
+	feed.ConsecutiveFailures = 0
+	feed.LastError = sql.NullString{}
+	s.data.feeds[feed.ID] = feed
+	return nil
+}
+
+func (s *memoryStore) MarkFeedFailed(ctx context.Context, arg database.MarkFeedFailedParams) error {
+	defer s.lock()()
+	feed, ok := s.data.feeds[arg.ID]
+	if !ok {
+		return nil
+	}
+	feed.UpdatedAt = time.Now()
+	feed.ConsecutiveFailures++
+	feed.LastError = arg.LastError
+	feed.LastStatus = arg.LastStatus
+	feed.NextFetchAt = arg.NextFetchAt
+	feed.DisabledAt = arg.DisabledAt
+	s.data.feeds[feed.ID] = feed
+	return nil
+}
+
+func (s *memoryStore) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	defer s.lock()()
+	user, ok := s.data.users[arg.UserID]
+	if !ok {
+		return database.CreateFeedFollowRow{}, errForeignKey("feed_follows_user_id_fkey")
+	}
+	feed, ok := s.data.feeds[arg.FeedID]
+	if !ok {
+		return database.CreateFeedFollowRow{}, errForeignKey("feed_follows_feed_id_fkey")
+	}
+	for _, follow := range s.data.follows {
+		if follow.UserID == arg.UserID && follow.FeedID == arg.FeedID {
+			return database.CreateFeedFollowRow{}, errUnique("feed_follows_user_id_feed_id_key")
+		}
+	}
+	s.data.follows[arg.ID] = database.FeedFollow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		Folder:    arg.Folder,
+	}
+	return database.CreateFeedFollowRow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		FeedName:  feed.Name,
+		UserName:  user.Name,
+	}, nil
+}
+
+func (s *memoryStore) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	defer s.lock()()
+	var items []database.GetFeedFollowsForUserRow
+	for _, follow := range s.data.follows {
+		if follow.UserID != userID {
+			continue
+		}
+		items = append(items, database.GetFeedFollowsForUserRow{
+			ID:          follow.ID,
+			CreatedAt:   follow.CreatedAt,
+			UpdatedAt:   follow.UpdatedAt,
+			FeedID:      follow.FeedID,
+			Folder:      follow.Folder,
+			FeedName:    s.data.feeds[follow.FeedID].Name,
+			FeedUrl:     s.data.feeds[follow.FeedID].Url,
+			FeedTitle:   s.data.feeds[follow.FeedID].Title,
+			FeedSiteUrl: s.data.feeds[follow.FeedID].SiteUrl,
+		})
+	}
+	slices.SortFunc(items, func(a, b database.GetFeedFollowsForUserRow) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return items, nil
+}
+
+func (s *memoryStore) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) (database.DeleteFeedFollowRow, error) {
+	defer s.lock()()
+	for id, follow := range s.data.follows {
+		feed := s.data.feeds[follow.FeedID]
+		if follow.UserID == arg.UserID && feed.UserID == arg.UserID && feed.Url == arg.Url {
+			delete(s.data.follows, id)
+			return database.DeleteFeedFollowRow{FeedID: follow.FeedID, UserID: follow.UserID}, nil
+		}
+	}
+	return database.DeleteFeedFollowRow{}, sql.ErrNoRows
+}
+
+func (s *memoryStore) RekeyLegacyPost(ctx context.Context, arg database.RekeyLegacyPostParams) (int64, error) {
+	defer s.lock()()
+	var legacyID uuid.UUID
+	for id, post := range s.data.posts {
+		if post.FeedID != arg.FeedID {
+			continue
+		}
+		if post.Guid == arg.Guid {
+			return 0, nil
+		}
+		if post.Guid == arg.Url && post.Url == arg.Url {
+			legacyID = id
+		}
+	}
+	post, ok := s.data.posts[legacyID]
+	if !ok {
+		return 0, nil
+	}
+	post.Guid = arg.Guid
+	s.data.posts[legacyID] = post
+	return 1, nil
+}
+
+func (s *memoryStore) UpsertPost(ctx context.Context, arg database.UpsertPostParams) (database.Post, error) {
+	defer s.lock()()
+	if _, ok := s.data.feeds[arg.FeedID]; !ok {
+		return database.Post{}, errForeignKey("posts_feed_id_fkey")
+	}
+	now := time.Now()
+	for id, post := range s.data.posts {
+		if post.FeedID != arg.FeedID || post.Guid != arg.Guid {
+			continue
+		}
+		if post.Title == arg.Title && post.Url == arg.Url && post.Description == arg.Description {
+			return database.Post{}, sql.ErrNoRows
+		}
+		post.Title = arg.Title
+		post.Url = arg.Url
+		post.Description = arg.Description
+		post.UpdatedAt = now
+		s.data.posts[id] = post
+		return post, nil
+	}
+	post := database.Post{
+		ID:          uuid.New(),
+		FeedID:      arg.FeedID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Title:       arg.Title,
+		Url:         arg.Url,
+		Description: arg.Description,
+		PublishedAt: arg.PublishedAt,
+		Guid:        arg.Guid,
+	}
+	s.data.posts[post.ID] = post
+	return post, nil
+}
+
+func (s *memoryStore) GetPost(ctx context.Context, id uuid.UUID) (database.Post, error) {
+	defer s.lock()()
+	post, ok := s.data.posts[id]
+	if !ok {
+		return database.Post{}, sql.ErrNoRows
+	}
+	return post, nil
+}
+
+// comparePosts orders posts by publication date, then id, like the keyset the SQL stores paginate on.
+func comparePosts(aPublishedAt time.Time, aID uuid.UUID, bPublishedAt time.Time, bID uuid.UUID) int {
+	if c := aPublishedAt.Compare(bPublishedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(aID.String(), bID.String())
+}
+
+func (s *memoryStore) GetPostsFromUser(ctx context.Context, arg database.GetPostsFromUserParams) ([]database.GetPostsFromUserRow, error) {
+	defer s.lock()()
+	followed := make(map[uuid.UUID]bool)
+	for _, follow := range s.data.follows {
+		if follow.UserID == arg.UserID {
+			followed[follow.FeedID] = true
+		}
+	}
+	direction := -1
+	if arg.OldestFirst {
+		direction = 1
+	}
+	var items []database.GetPostsFromUserRow
+	for _, post := range s.data.posts {
+		if !followed[post.FeedID] || arg.FeedID.Valid && post.FeedID != arg.FeedID.UUID {
+			continue
+		}
+		if arg.Since.Valid && post.PublishedAt.Before(arg.Since.Time) || arg.Until.Valid && !post.PublishedAt.Before(arg.Until.Time) {
+			continue
+		}
+		if arg.AfterPublishedAt.Valid && comparePosts(post.PublishedAt, post.ID, arg.AfterPublishedAt.Time, arg.AfterID.UUID)*direction <= 0 {
+			continue
+		}
+		var readAt sql.NullTime
+		if read, ok := s.data.reads[userPostKey{arg.UserID, post.ID}]; ok {
+			if !arg.IncludeRead {
+				continue
+			}
+			readAt = sql.NullTime{Time: read.ReadAt, Valid: true}
+		}
+		items = append(items, database.GetPostsFromUserRow{
+			ID:          post.ID,
+			FeedID:      post.FeedID,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+			Title:       post.Title,
+			Url:         post.Url,
+			Description: post.Description,
+			PublishedAt: post.PublishedAt,
+			Guid:        post.Guid,
+			ReadAt:      readAt,
+		})
+	}
+	slices.SortFunc(items, func(a, b database.GetPostsFromUserRow) int {
+		return comparePosts(a.PublishedAt, a.ID, b.PublishedAt, b.ID) * direction
+	})
+	items = items[min(int(arg.Offset), len(items)):]
+	return head(items, int(arg.Limit)), nil
+}
+
+func (s *memoryStore) GetRecentPublishedDates(ctx context.Context, feedID uuid.UUID) ([]time.Time, error) {
+	defer s.lock()()
+	var dates []time.Time
+	for _, post := range s.data.posts {
+		if post.FeedID == feedID {
+			dates = append(dates, post.PublishedAt)
+		}
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return b.Compare(a) })
+	return head(dates, 10), nil
+}
+
+func (s *memoryStore) SearchPosts(ctx context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error) {
+	query, err := parseSearchQuery(arg.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer s.lock()()
+	var items []database.SearchPostsRow
+	for _, post := range s.data.posts {
+		if !s.isFollowing(arg.UserID, post.FeedID) {
+			continue
+		}
+		rank := query.matches(post.Title + "\n" + post.Description)
+		if rank == 0 {
+			continue
+		}
+		items = append(items, database.SearchPostsRow{
+			ID:           post.ID,
+			Title:        post.Title,
+			Url:          post.Url,
+			PublishedAt:  post.PublishedAt,
+			FeedName:     s.data.feeds[post.FeedID].Name,
+			TitleSnippet: post.Title,
+			Snippet:      post.Description,
+			Rank:         float32(rank),
+		})
+	}
+	slices.SortFunc(items, func(a, b database.SearchPostsRow) int {
+		if a.Rank != b.Rank {
+			return cmp.Compare(b.Rank, a.Rank)
+		}
+		return b.PublishedAt.Compare(a.PublishedAt)
+	})
+	return head(items, int(arg.Limit)), nil
+}
+
+func (s *memoryStore) isFollowing(userID, feedID uuid.UUID) bool {
+	for _, follow := range s.data.follows {
+		if follow.UserID == userID && follow.FeedID == feedID {
+			return true
+		}
+	}
+	return false
+}
+
+// markRead marks the post as read by the user, unless it already is. It reports whether the post was marked.
+func (s *memoryStore) markRead(userID uuid.UUID, post database.Post, readAt time.Time) bool {
+	key := userPostKey{userID, post.ID}
+	if _, ok := s.data.reads[key]; ok {
+		return false
+	}
+	s.data.reads[key] = database.PostRead{UserID: userID, PostID: post.ID, ReadAt: readAt}
+	return true
+}
+
+func (s *memoryStore) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) (database.PostRead, error) {
+	defer s.lock()()
+	post, ok := s.data.posts[arg.PostID]
+	if !ok || !s.isFollowing(arg.UserID, post.FeedID) {
+		return database.PostRead{}, sql.ErrNoRows
+	}
+	s.markRead(arg.UserID, post, time.Now())
+	return s.data.reads[userPostKey{arg.UserID, post.ID}], nil
+}
+
+func (s *memoryStore) MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) error {
+	defer s.lock()()
+	delete(s.data.reads, userPostKey{arg.UserID, arg.PostID})
+	return nil
+}
+
+func (s *memoryStore) MarkAllPostsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	defer s.lock()()
+	var marked int64
+	readAt := time.Now()
+	for _, post := range s.data.posts {
+		if s.isFollowing(userID, post.FeedID) && s.markRead(userID, post, readAt) {
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+func (s *memoryStore) MarkFeedPostsRead(ctx context.Context, arg database.MarkFeedPostsReadParams) (int64, error) {
+	defer s.lock()()
+	if !s.isFollowing(arg.UserID, arg.FeedID) {
+		return 0, nil
+	}
+	var marked int64
+	readAt := time.Now()
+	for _, post := range s.data.posts {
+		if post.FeedID == arg.FeedID && s.markRead(arg.UserID, post, readAt) {
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+func (s *memoryStore) CreateBookmark(ctx context.Context, arg database.CreateBookmarkParams) (database.Bookmark, error) {
+	defer s.lock()()
+	post, ok := s.data.posts[arg.PostID]
+	if !ok || !s.isFollowing(arg.UserID, post.FeedID) {
+		return database.Bookmark{}, sql.ErrNoRows
+	}
+	key := userPostKey{arg.UserID, post.ID}
+	if bookmark, ok := s.data.bookmarks[key]; ok {
+		return bookmark, nil
+	}
+	bookmark := database.Bookmark{
+		UserID:      arg.UserID,
+		PostID:      post.ID,
+		CreatedAt:   time.Now(),
+		FeedName:    s.data.feeds[post.FeedID].Name,
+		Title:       post.Title,
+		Url:         post.Url,
+		Description: post.Description,
+		PublishedAt: post.PublishedAt,
+	}
+	s.data.bookmarks[key] = bookmark
+	return bookmark, nil
+}
+
+func (s *memoryStore) DeleteBookmark(ctx context.Context, arg database.DeleteBookmarkParams) (int64, error) {
+	defer s.lock()()
+	key := userPostKey{arg.UserID, arg.PostID}
+	if _, ok := s.data.bookmarks[key]; !ok {
+		return 0, nil
+	}
+	delete(s.data.bookmarks, key)
+	return 1, nil
+}
+
+func (s *memoryStore) GetBookmarksForUser(ctx context.Context, userID uuid.UUID) ([]database.Bookmark, error) {
+	defer s.lock()()
+	var items []database.Bookmark
+	for _, bookmark := range s.data.bookmarks {
+		if bookmark.UserID == userID {
+			items = append(items, bookmark)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Bookmark) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return items, nil
+}
+
+// head applies LIMIT to items.
+func head[T any](items []T, limit int) []T {
+	if limit < len(items) {
+		return items[:limit]
+	}
+	return items
+}
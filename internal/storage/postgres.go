@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charlesaraya/gator/internal/database"
+)
+
+// postgresStore runs the sqlc-generated PostgreSQL queries directly.
+type postgresStore struct {
+	*database.Queries
+	db *sql.DB
+}
+
+func NewPostgres(db *sql.DB) Store {
+	return &postgresStore{
+		Queries: database.New(db),
+		db:      db,
+	}
+}
+
+func (s *postgresStore) InTx(ctx context.Context, fn func(Store) error) error {
+	return inTx(ctx, s.db, func(tx *sql.Tx) error {
+		return fn(&postgresStore{Queries: s.Queries.WithTx(tx), db: s.db})
+	})
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// searchQuery is a web-style search query, as understood by PostgreSQL's websearch_to_tsquery:
+// words and "quoted phrases" must all match, OR separates alternatives and a leading - excludes a word or phrase.
+type searchQuery []searchGroup
+
+// searchGroup is one alternative of a searchQuery, whose terms must all match.
+type searchGroup []searchTerm
+
+type searchTerm struct {
+	text    string
+	negated bool
+}
+
+func parseSearchQuery(query string) (searchQuery, error) {
+	var groups searchQuery
+	var group searchGroup
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		negated := strings.HasPrefix(rest, "-")
+		if negated {
+			rest = rest[1:]
+		}
+		var text string
+		quoted := strings.HasPrefix(rest, `"`)
+		if quoted {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				text, rest = rest[1:], ""
+			} else {
+				text, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			text, rest = rest[:end], rest[end:]
+		}
+		rest = strings.TrimSpace(rest)
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			continue
+		}
+		if !quoted && !negated && strings.EqualFold(text, "or") {
+			if len(group) > 0 {
+				groups = append(groups, group)
+				group = nil
+			}
+			continue
+		}
+		group = append(group, searchTerm{text: text, negated: negated})
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	if len(groups) == 0 {
+		return nil, errors.New("empty search query")
+	}
+	for _, group := range groups {
+		if !group.hasPositive() {
+			return nil, fmt.Errorf("search query '%s' only excludes terms", query)
+		}
+	}
+	return groups, nil
+}
+
+func (group searchGroup) hasPositive() bool {
+	for _, term := range group {
+		if !term.negated {
+			return true
+		}
+	}
+	return false
+}
+
+// fts5 renders the query as an SQLite FTS5 match expression, quoting every word and phrase.
+func (q searchQuery) fts5() string {
+	groups := make([]string, 0, len(q))
+	for _, group := range q {
+		var positive, negative []string
+		for _, term := range group {
+			quoted := `"` + strings.ReplaceAll(term.text, `"`, `""`) + `"`
+			if term.negated {
+				negative = append(negative, "NOT "+quoted)
+			} else {
+				positive = append(positive, quoted)
+			}
+		}
+		groups = append(groups, strings.Join(append(positive, negative...), " "))
+	}
+	return strings.Join(groups, " OR ")
+}
+
+// matches reports how many times the query's terms occur in text, or 0 if text doesn't match.
+func (q searchQuery) matches(text string) int {
+	text = strings.ToLower(text)
+	best := 0
+	for _, group := range q {
+		hits := 0
+		for _, term := range group {
+			count := strings.Count(text, strings.ToLower(term.text))
+			if term.negated && count > 0 || !term.negated && count == 0 {
+				hits = 0
+				break
+			}
+			hits += count
+		}
+		best = max(best, hits)
+	}
+	return best
+}
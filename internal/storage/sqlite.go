@@ -0,0 +1,443 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/charlesaraya/gator/internal/database"
+	"github.com/charlesaraya/gator/internal/database/sqlite"
+	"github.com/google/uuid"
+)
+
+// claimLease is how long a claimed feed is kept from other workers while it's being fetched.
+const claimLease = 10 * time.Minute
+
+// sqliteStore runs the sqlc-generated SQLite queries, filling in the ids and timestamps that
+// PostgreSQL generates itself and converting the results into the common model.
+// Timestamps are stored in UTC so they compare correctly as text.
+type sqliteStore struct {
+	q  *sqlite.Queries
+	db *sql.DB
+}
+
+func NewSQLite(db *sql.DB) Store {
+	return &sqliteStore{
+		q:  sqlite.New(db),
+		db: db,
+	}
+}
+
+func (s *sqliteStore) InTx(ctx context.Context, fn func(Store) error) error {
+	return inTx(ctx, s.db, func(tx *sql.Tx) error {
+		return fn(&sqliteStore{q: s.q.WithTx(tx), db: s.db})
+	})
+}
+
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func utcNullTime(t sql.NullTime) sql.NullTime {
+	return sql.NullTime{Time: t.Time.UTC(), Valid: t.Valid}
+}
+
+func toNullInt64(i sql.NullInt32) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(i.Int32), Valid: i.Valid}
+}
+
+func toNullInt32(i sql.NullInt64) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(i.Int64), Valid: i.Valid}
+}
+
+func toUser(user sqlite.User) database.User {
+	return database.User{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Name:      user.Name,
+	}
+}
+
+func toFeed(feed sqlite.Feed) database.Feed {
+	return database.Feed{
+		ID:                  feed.ID,
+		UserID:              feed.UserID,
+		CreatedAt:           feed.CreatedAt,
+		UpdatedAt:           feed.UpdatedAt,
+		Name:                feed.Name,
+		Url:                 feed.Url,
+		LastFetchedAt:       feed.LastFetchedAt,
+		Etag:                feed.Etag,
+		LastModified:        feed.LastModified,
+		NextFetchAt:         feed.NextFetchAt,
+		ConsecutiveFailures: int32(feed.ConsecutiveFailures),
+		LastError:           feed.LastError,
+		LastStatus:          toNullInt32(feed.LastStatus),
+		DisabledAt:          feed.DisabledAt,
+		Title:               feed.Title,
+		Description:         feed.Description,
+		SiteUrl:             feed.SiteUrl,
+		Language:            feed.Language,
+		IconUrl:             feed.IconUrl,
+	}
+}
+
+func toPost(post sqlite.Post) database.Post {
+	return database.Post{
+		ID:          post.ID,
+		FeedID:      post.FeedID,
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+		Title:       post.Title,
+		Url:         post.Url,
+		Description: post.Description,
+		PublishedAt: post.PublishedAt,
+		Guid:        post.Guid,
+	}
+}
+
+func toBookmark(bookmark sqlite.Bookmark) database.Bookmark {
+	return database.Bookmark{
+		UserID:      bookmark.UserID,
+		PostID:      bookmark.PostID,
+		CreatedAt:   bookmark.CreatedAt,
+		FeedName:    bookmark.FeedName,
+		Title:       bookmark.Title,
+		Url:         bookmark.Url,
+		Description: bookmark.Description,
+		PublishedAt: bookmark.PublishedAt,
+	}
+}
+
+func (s *sqliteStore) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	user, err := s.q.CreateUser(ctx, sqlite.CreateUserParams{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt.UTC(),
+		UpdatedAt: arg.UpdatedAt.UTC(),
+		Name:      arg.Name,
+	})
+	return toUser(user), err
+}
+
+func (s *sqliteStore) GetUser(ctx context.Context, name string) (database.User, error) {
+	user, err := s.q.GetUser(ctx, name)
+	return toUser(user), err
+}
+
+func (s *sqliteStore) GetUsers(ctx context.Context) ([]database.User, error) {
+	users, err := s.q.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.User, 0, len(users))
+	for _, user := range users {
+		items = append(items, toUser(user))
+	}
+	return items, nil
+}
+
+func (s *sqliteStore) DeleteUsers(ctx context.Context) error {
+	return s.q.DeleteUsers(ctx)
+}
+
+func (s *sqliteStore) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	feed, err := s.q.CreateFeed(ctx, sqlite.CreateFeedParams{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt.UTC(),
+		UpdatedAt:   arg.UpdatedAt.UTC(),
+		Name:        arg.Name,
+		Url:         arg.Url,
+		UserID:      arg.UserID,
+		Title:       arg.Title,
+		Description: arg.Description,
+		SiteUrl:     arg.SiteUrl,
+		Language:    arg.Language,
+		IconUrl:     arg.IconUrl,
+	})
+	return toFeed(feed), err
+}
+
+func (s *sqliteStore) GetFeed(ctx context.Context, url string) (database.Feed, error) {
+	feed, err := s.q.GetFeed(ctx, url)
+	return toFeed(feed), err
+}
+
+func (s *sqliteStore) GetUserFeeds(ctx context.Context) ([]database.GetUserFeedsRow, error) {
+	feeds, err := s.q.GetUserFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetUserFeedsRow, 0, len(feeds))
+	for _, feed := range feeds {
+		items = append(items, database.GetUserFeedsRow{
+			ID:                  feed.ID,
+			CreatedAt:           feed.CreatedAt,
+			UpdatedAt:           feed.UpdatedAt,
+			Name:                feed.Name,
+			Url:                 feed.Url,
+			ConsecutiveFailures: int32(feed.ConsecutiveFailures),
+			LastError:           feed.LastError,
+			DisabledAt:          feed.DisabledAt,
+			Title:               feed.Title,
+			Description:         feed.Description,
+			SiteUrl:             feed.SiteUrl,
+			Language:            feed.Language,
+			UserName:            feed.UserName,
+		})
+	}
+	return items, nil
+}
+
+func (s *sqliteStore) DeleteFeed(ctx context.Context, url string) (int64, error) {
+	return s.q.DeleteFeed(ctx, url)
+}
+
+func (s *sqliteStore) EnableFeed(ctx context.Context, url string) (int64, error) {
+	return s.q.EnableFeed(ctx, sqlite.EnableFeedParams{
+		UpdatedAt: now(),
+		Url:       url,
+	})
+}
+
+func (s *sqliteStore) ClaimNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	claimedAt := now()
+	feed, err := s.q.ClaimNextFeedToFetch(ctx, sqlite.ClaimNextFeedToFetchParams{
+		Now:        sql.NullTime{Time: claimedAt, Valid: true},
+		LeaseUntil: sql.NullTime{Time: claimedAt.Add(claimLease), Valid: true},
+	})
+	return toFeed(feed), err
+}
+
+func (s *sqliteStore) MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error {
+	return s.q.MarkFeedFetched(ctx, sqlite.MarkFeedFetchedParams{
+		Now:          sql.NullTime{Time: now(), Valid: true},
+		Etag:         arg.Etag,
+		LastModified: arg.LastModified,
+		NextFetchAt:  utcNullTime(arg.NextFetchAt),
+		LastStatus:   toNullInt64(arg.LastStatus),
+		ID:           arg.ID,
+	})
+}
+
+func (s *sqliteStore) MarkFeedFailed(ctx context.Context, arg database.MarkFeedFailedParams) error {
+	return s.q.MarkFeedFailed(ctx, sqlite.MarkFeedFailedParams{
+		UpdatedAt:   now(),
+		LastError:   arg.LastError,
+		LastStatus:  toNullInt64(arg.LastStatus),
+		NextFetchAt: utcNullTime(arg.NextFetchAt),
+		DisabledAt:  utcNullTime(arg.DisabledAt),
+		ID:          arg.ID,
+	})
+}
+
+func (s *sqliteStore) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	row, err := s.q.CreateFeedFollow(ctx, sqlite.CreateFeedFollowParams{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt.UTC(),
+		UpdatedAt: arg.UpdatedAt.UTC(),
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		Folder:    arg.Folder,
+	})
+	return database.CreateFeedFollowRow{
+		ID:        row.ID,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+		FeedName:  row.FeedName,
+		UserName:  row.UserName,
+	}, err
+}
+
+func (s *sqliteStore) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	follows, err := s.q.GetFeedFollowsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetFeedFollowsForUserRow, 0, len(follows))
+	for _, follow := range follows {
+		items = append(items, database.GetFeedFollowsForUserRow{
+			ID:          follow.ID,
+			CreatedAt:   follow.CreatedAt,
+			UpdatedAt:   follow.UpdatedAt,
+			FeedID:      follow.FeedID,
+			Folder:      follow.Folder,
+			FeedName:    follow.FeedName,
+			FeedUrl:     follow.FeedUrl,
+			FeedTitle:   follow.FeedTitle,
+			FeedSiteUrl: follow.FeedSiteUrl,
+		})
+	}
+	return items, nil
+}
+
+func (s *sqliteStore) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) (database.DeleteFeedFollowRow, error) {
+	row, err := s.q.DeleteFeedFollow(ctx, sqlite.DeleteFeedFollowParams{
+		UserID: arg.UserID,
+		Url:    arg.Url,
+	})
+	return database.DeleteFeedFollowRow{
+		FeedID: row.FeedID,
+		UserID: row.UserID,
+	}, err
+}
+
+func (s *sqliteStore) RekeyLegacyPost(ctx context.Context, arg database.RekeyLegacyPostParams) (int64, error) {
+	return s.q.RekeyLegacyPost(ctx, sqlite.RekeyLegacyPostParams{
+		Guid:   arg.Guid,
+		FeedID: arg.FeedID,
+		Url:    arg.Url,
+	})
+}
+
+func (s *sqliteStore) UpsertPost(ctx context.Context, arg database.UpsertPostParams) (database.Post, error) {
+	upsertedAt := now()
+	post, err := s.q.UpsertPost(ctx, sqlite.UpsertPostParams{
+		ID:          uuid.New(),
+		FeedID:      arg.FeedID,
+		CreatedAt:   upsertedAt,
+		UpdatedAt:   upsertedAt,
+		Title:       arg.Title,
+		Url:         arg.Url,
+		Description: arg.Description,
+		PublishedAt: arg.PublishedAt.UTC(),
+		Guid:        arg.Guid,
+	})
+	return toPost(post), err
+}
+
+func (s *sqliteStore) GetPost(ctx context.Context, id uuid.UUID) (database.Post, error) {
+	post, err := s.q.GetPost(ctx, id)
+	return toPost(post), err
+}
+
+func (s *sqliteStore) GetPostsFromUser(ctx context.Context, arg database.GetPostsFromUserParams) ([]database.GetPostsFromUserRow, error) {
+	posts, err := s.q.GetPostsFromUser(ctx, sqlite.GetPostsFromUserParams{
+		UserID:           arg.UserID,
+		IncludeRead:      arg.IncludeRead,
+		FeedID:           arg.FeedID,
+		Since:            utcNullTime(arg.Since),
+		Until:            utcNullTime(arg.Until),
+		AfterPublishedAt: utcNullTime(arg.AfterPublishedAt),
+		OldestFirst:      arg.OldestFirst,
+		AfterID:          arg.AfterID,
+		Limit:            int64(arg.Limit),
+		Offset:           int64(arg.Offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetPostsFromUserRow, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, database.GetPostsFromUserRow{
+			ID:          post.ID,
+			FeedID:      post.FeedID,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+			Title:       post.Title,
+			Url:         post.Url,
+			Description: post.Description,
+			PublishedAt: post.PublishedAt,
+			Guid:        post.Guid,
+			ReadAt:      post.ReadAt,
+		})
+	}
+	return items, nil
+}
+
+func (s *sqliteStore) GetRecentPublishedDates(ctx context.Context, feedID uuid.UUID) ([]time.Time, error) {
+	return s.q.GetRecentPublishedDates(ctx, feedID)
+}
+
+func (s *sqliteStore) SearchPosts(ctx context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error) {
+	query, err := parseSearchQuery(arg.Query)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.q.SearchPosts(ctx, sqlite.SearchPostsParams{
+		Query:  query.fts5(),
+		UserID: arg.UserID,
+		Limit:  int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.SearchPostsRow, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, database.SearchPostsRow{
+			ID:           post.ID,
+			Title:        post.Title,
+			Url:          post.Url,
+			PublishedAt:  post.PublishedAt,
+			FeedName:     post.FeedName,
+			TitleSnippet: post.TitleSnippet,
+			Snippet:      post.Snippet,
+			// bm25 scores better matches lower
+			Rank: float32(-post.Rank),
+		})
+	}
+	return items, nil
+}
+
+func (s *sqliteStore) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) (database.PostRead, error) {
+	read, err := s.q.MarkPostRead(ctx, sqlite.MarkPostReadParams{
+		ReadAt: now(),
+		UserID: arg.UserID,
+		PostID: arg.PostID,
+	})
+	return database.PostRead{
+		UserID: read.UserID,
+		PostID: read.PostID,
+		ReadAt: read.ReadAt,
+	}, err
+}
+
+func (s *sqliteStore) MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) error {
+	return s.q.MarkPostUnread(ctx, sqlite.MarkPostUnreadParams{
+		UserID: arg.UserID,
+		PostID: arg.PostID,
+	})
+}
+
+func (s *sqliteStore) MarkAllPostsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.q.MarkAllPostsRead(ctx, sqlite.MarkAllPostsReadParams{
+		ReadAt: now(),
+		UserID: userID,
+	})
+}
+
+func (s *sqliteStore) MarkFeedPostsRead(ctx context.Context, arg database.MarkFeedPostsReadParams) (int64, error) {
+	return s.q.MarkFeedPostsRead(ctx, sqlite.MarkFeedPostsReadParams{
+		ReadAt: now(),
+		UserID: arg.UserID,
+		FeedID: arg.FeedID,
+	})
+}
+
+func (s *sqliteStore) CreateBookmark(ctx context.Context, arg database.CreateBookmarkParams) (database.Bookmark, error) {
+	bookmark, err := s.q.CreateBookmark(ctx, sqlite.CreateBookmarkParams{
+		CreatedAt: now(),
+		UserID:    arg.UserID,
+		PostID:    arg.PostID,
+	})
+	return toBookmark(bookmark), err
+}
+
+func (s *sqliteStore) DeleteBookmark(ctx context.Context, arg database.DeleteBookmarkParams) (int64, error) {
+	return s.q.DeleteBookmark(ctx, sqlite.DeleteBookmarkParams{
+		UserID: arg.UserID,
+		PostID: arg.PostID,
+	})
+}
+
+func (s *sqliteStore) GetBookmarksForUser(ctx context.Context, userID uuid.UUID) ([]database.Bookmark, error) {
+	bookmarks, err := s.q.GetBookmarksForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.Bookmark, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		items = append(items, toBookmark(bookmark))
+	}
+	return items, nil
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/charlesaraya/gator/internal/config"
+	"github.com/charlesaraya/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// Store is the set of queries gator runs against its database, implemented for every supported backend.
+// It uses the types generated for PostgreSQL as the common model.
+type Store interface {
+	CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error)
+	GetUser(ctx context.Context, name string) (database.User, error)
+	GetUsers(ctx context.Context) ([]database.User, error)
+	DeleteUsers(ctx context.Context) error
+
+	CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error)
+	GetFeed(ctx context.Context, url string) (database.Feed, error)
+	GetUserFeeds(ctx context.Context) ([]database.GetUserFeedsRow, error)
+	DeleteFeed(ctx context.Context, url string) (int64, error)
+	EnableFeed(ctx context.Context, url string) (int64, error)
+	ClaimNextFeedToFetch(ctx context.Context) (database.Feed, error)
+	MarkFeedFetched(ctx context.Context, arg database.MarkFeedFetchedParams) error
+	MarkFeedFailed(ctx context.Context, arg database.MarkFeedFailedParams) error
+
+	CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error)
+	GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error)
+	DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) (database.DeleteFeedFollowRow, error)
+
+	// RekeyLegacyPost gives the post keyed by its url, as posts stored before guids were, the item's guid.
+	RekeyLegacyPost(ctx context.Context, arg database.RekeyLegacyPostParams) (int64, error)
+	UpsertPost(ctx context.Context, arg database.UpsertPostParams) (database.Post, error)
+	GetPost(ctx context.Context, id uuid.UUID) (database.Post, error)
+	GetPostsFromUser(ctx context.Context, arg database.GetPostsFromUserParams) ([]database.GetPostsFromUserRow, error)
+	GetRecentPublishedDates(ctx context.Context, feedID uuid.UUID) ([]time.Time, error)
+	// SearchPosts ranks the posts of the user's followed feeds against a web-style search query.
+	SearchPosts(ctx context.Context, arg database.SearchPostsParams) ([]database.SearchPostsRow, error)
+
+	MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) (database.PostRead, error)
+	MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) error
+	MarkAllPostsRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkFeedPostsRead(ctx context.Context, arg database.MarkFeedPostsReadParams) (int64, error)
+
+	CreateBookmark(ctx context.Context, arg database.CreateBookmarkParams) (database.Bookmark, error)
+	DeleteBookmark(ctx context.Context, arg database.DeleteBookmarkParams) (int64, error)
+	GetBookmarksForUser(ctx context.Context, userID uuid.UUID) ([]database.Bookmark, error)
+
+	// InTx runs fn with a Store bound to a transaction, committing it if fn succeeds and rolling it back otherwise.
+	InTx(ctx context.Context, fn func(Store) error) error
+}
+
+// New returns the Store for the database opened with the given driver.
+func New(db *sql.DB, driver string) (Store, error) {
+	switch driver {
+	case config.POSTGRES_DRIVER:
+		return NewPostgres(db), nil
+	case config.SQLITE_DRIVER:
+		return NewSQLite(db), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver '%s'", driver)
+	}
+}
+
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err = fn(tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/charlesaraya/gator/internal/commands"
 	"github.com/charlesaraya/gator/internal/config"
-	"github.com/charlesaraya/gator/internal/database"
+	"github.com/charlesaraya/gator/internal/migrate"
+	"github.com/charlesaraya/gator/internal/storage"
 )
 
 func main() {
@@ -19,30 +23,31 @@ func main() {
 		log.Fatalf("loading DB failed, %s", err.Error())
 	}
 	defer db.Close()
+	driver, _, err := cfg.Driver()
+	if err != nil {
+		log.Fatalf("loading DB failed, %s", err.Error())
+	}
+	store, err := storage.New(db, driver)
+	if err != nil {
+		log.Fatalf("loading DB failed, %s", err.Error())
+	}
 	state := commands.State{
-		Db:     database.New(db),
+		Db:     store,
+		Conn:   db,
 		Config: &cfg,
+		In:     os.Stdin,
+		Out:    os.Stdout,
 	}
 
 	cmds := commands.GetCommands()
-	cmds.Register("login", commands.LoginHandler)
-	cmds.Register("register", commands.RegisterHandler)
-	cmds.Register("users", commands.UsersHandler)
-	cmds.Register("reset", commands.ResetHandler)
-	cmds.Register("agg", commands.AggregateFeedHandler)
-	cmds.Register("addfeed", commands.LoggedInMiddleware(commands.AddFeedHandler))
-	cmds.Register("delfeed", commands.DeleteFeedHandler)
-	cmds.Register("feeds", commands.FeedsHandler)
-	cmds.Register("follow", commands.LoggedInMiddleware(commands.FollowFeedsHandler))
-	cmds.Register("following", commands.LoggedInMiddleware(commands.FollowedFeedsHandler))
-	cmds.Register("unfollow", commands.LoggedInMiddleware(commands.UnFollowFeedHandler))
-	cmds.Register("browse", commands.LoggedInMiddleware(commands.BrowsePostsHandler))
 
 	var cliCommand commands.Command
 	switch len(os.Args) {
 	case 1:
-		log.Fatal("not enough arguments were provided")
-		log.Fatal("Usage: cli <command> [args...]")
+		cliCommand = commands.Command{
+			Name:      "help",
+			Arguments: []string{},
+		}
 	case 2:
 		cliCommand = commands.Command{
 			Name:      os.Args[1],
@@ -54,7 +59,14 @@ func main() {
 			Arguments: os.Args[2:],
 		}
 	}
-	if err = cmds.Run(&state, cliCommand); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if spec, ok := cmds.Lookup(cliCommand.Name); ok && !spec.SkipSchemaCheck {
+		if err = migrate.Check(ctx, db, driver); err != nil {
+			log.Fatalf("checking DB schema failed, %s", err.Error())
+		}
+	}
+	if err = cmds.Run(ctx, &state, cliCommand); err != nil {
 		log.Fatalf("running command failed, %s", err.Error())
 	}
 }